@@ -13,12 +13,15 @@ import (
 )
 
 func main() {
-	var filename string
+	var filename, debugDir, from, angles string
 	var debug, show bool
 
 	flag.StringVar(&filename, "f", "", "file to work with")
 	flag.BoolVar(&debug, "d", false, "enable debug mode")
+	flag.StringVar(&debugDir, "debug-dir", ".", "directory debug runs are written under")
 	flag.BoolVar(&show, "s", false, "visualise image")
+	flag.StringVar(&from, "from", "", "resume from a JSON stage dump given by -f: pixels, gradients or hist")
+	flag.StringVar(&angles, "angles", "", "angles JSON file when resuming from gradients, with -f the magnitudes")
 
 	flag.Parse()
 
@@ -26,20 +29,19 @@ func main() {
 		log.Fatal("Missing required filename")
 	}
 
-	h := hog.NewHOG(nil, nil)
-
-	reader, err := os.Open(filename)
+	h, err := hog.NewHOG()
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer reader.Close()
 
-	img, _, err := image.Decode(reader)
-	if err != nil {
-		log.Fatal(err)
-	}
+	var hogImg image.Image
+	var features []float32
 
-	hogImg, features, err := h.HOG(img, debug)
+	if from != "" {
+		hogImg, features, err = resume(h, from, filename, angles)
+	} else {
+		hogImg, features, err = extract(h, filename, debug, debugDir)
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -77,3 +79,54 @@ func main() {
 		}
 	}
 }
+
+func extract(h *hog.HOG, filename string, debug bool, debugDir string) (image.Image, []float32, error) {
+	reader, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+
+	img, _, err := image.Decode(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sink hog.Sink
+
+	if debug {
+		runSink, err := hog.NewRunSink(debugDir)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		log.Printf("Writing debug artifacts to %s", runSink.Dir)
+
+		sink = runSink
+	}
+
+	return h.HOG(img, sink)
+}
+
+func resume(h *hog.HOG, from, filename, angles string) (image.Image, []float32, error) {
+	stage, err := hog.ParseStage(from)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paths := []string{filename}
+	if angles != "" {
+		paths = append(paths, angles)
+	}
+
+	trace, err := hog.LoadStage(stage, paths...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := h.Resume(stage, trace); err != nil {
+		return nil, nil, err
+	}
+
+	return trace.Image, trace.Features, nil
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/kachaje/hog/hog"
+)
+
+// tensor is a stage flattened row-major with the length of every axis.
+type tensor struct {
+	shape []int
+	data  []float64
+}
+
+func planeTensor(p hog.Plane) tensor {
+	t := tensor{shape: []int{p.Height, p.Width}}
+
+	for y := range p.Height {
+		for _, value := range p.Row(y) {
+			t.data = append(t.data, float64(value))
+		}
+	}
+
+	return t
+}
+
+func gridTensor(g hog.Grid) tensor {
+	t := tensor{shape: []int{g.Rows, g.Cols, g.Depth}}
+
+	for _, value := range g.Flatten() {
+		t.data = append(t.data, float64(value))
+	}
+
+	return t
+}
+
+// readTensor reads a JSON array nested to any depth, which must be
+// rectangular.
+func readTensor(path string) (tensor, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return tensor{}, err
+	}
+
+	var value any
+
+	if err := json.Unmarshal(content, &value); err != nil {
+		return tensor{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	t := tensor{}
+
+	for inner := value; ; {
+		list, ok := inner.([]any)
+		if !ok {
+			break
+		}
+
+		t.shape = append(t.shape, len(list))
+
+		if len(list) == 0 {
+			break
+		}
+
+		inner = list[0]
+	}
+
+	if err := t.fill(value, 0); err != nil {
+		return tensor{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return t, nil
+}
+
+func (t *tensor) fill(value any, depth int) error {
+	if depth == len(t.shape) {
+		number, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("expected a number at depth %d, got %T", depth, value)
+		}
+
+		t.data = append(t.data, number)
+
+		return nil
+	}
+
+	list, ok := value.([]any)
+	if !ok || len(list) != t.shape[depth] {
+		return fmt.Errorf("array is not rectangular at depth %d", depth)
+	}
+
+	for _, item := range list {
+		if err := t.fill(item, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// index converts a flat offset into coordinates.
+func (t tensor) index(offset int) []int {
+	result := make([]int, len(t.shape))
+
+	for axis := len(t.shape) - 1; axis >= 0; axis-- {
+		result[axis] = offset % t.shape[axis]
+		offset /= t.shape[axis]
+	}
+
+	return result
+}
+
+// Number is a float64 that encodes NaN and the infinities as the JSON
+// strings "NaN", "+Inf" and "-Inf", which encoding/json refuses to write as
+// numbers.
+type Number float64
+
+func (n Number) MarshalJSON() ([]byte, error) {
+	x := float64(n)
+
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return strconv.AppendQuote(nil, strconv.FormatFloat(x, 'g', -1, 64)), nil
+	}
+
+	return strconv.AppendFloat(nil, x, 'g', -1, 64), nil
+}
+
+type Offender struct {
+	Index     []int  `json:"index"`
+	Go        Number `json:"go"`
+	Reference Number `json:"reference"`
+	Diff      Number `json:"diff"`
+}
+
+// StageReport summarizes one stage. Elements where either side is NaN are
+// counted in NaN and OverTolerance but left out of MaxAbs and MeanAbs.
+type StageReport struct {
+	Stage          string     `json:"stage"`
+	Reference      string     `json:"reference"`
+	Shape          []int      `json:"shape,omitempty"`
+	ReferenceShape []int      `json:"referenceShape,omitempty"`
+	Skipped        string     `json:"skipped,omitempty"`
+	Count          int        `json:"count"`
+	NaN            int        `json:"nan"`
+	MaxAbs         Number     `json:"maxAbs"`
+	MeanAbs        Number     `json:"meanAbs"`
+	OverTolerance  int        `json:"overTolerance"`
+	Worst          []Offender `json:"worst"`
+}
+
+// byDiff orders offenders from the largest difference down, NaN first.
+func byDiff(a, b Offender) int {
+	aNaN, bNaN := math.IsNaN(float64(a.Diff)), math.IsNaN(float64(b.Diff))
+
+	if aNaN || bNaN {
+		return cmp.Compare(btoi(bNaN), btoi(aNaN))
+	}
+
+	return cmp.Compare(b.Diff, a.Diff)
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// compare reports how far actual is from reference. Shapes must match; the
+// worst offenders are the largest absolute differences, at most worst of
+// them.
+func compare(stage string, actual, reference tensor, tolerance float64, worst int) StageReport {
+	report := StageReport{Stage: stage, Shape: actual.shape, Worst: []Offender{}}
+
+	if !slices.Equal(actual.shape, reference.shape) {
+		report.ReferenceShape = reference.shape
+		report.Skipped = "shapes differ"
+
+		return report
+	}
+
+	report.Count = len(actual.data)
+
+	offenders := []Offender{}
+	sum := 0.0
+
+	for i, value := range actual.data {
+		diff := math.Abs(value - reference.data[i])
+
+		// A NaN on either side, or infinities of the same sign, always
+		// counts as an offender ahead of any finite difference
+		nan := math.IsNaN(diff)
+
+		if nan {
+			report.NaN++
+		} else {
+			sum += diff
+			report.MaxAbs = max(report.MaxAbs, Number(diff))
+		}
+
+		if nan || diff > tolerance {
+			report.OverTolerance++
+
+			offenders = append(offenders, Offender{
+				Index:     actual.index(i),
+				Go:        Number(value),
+				Reference: Number(reference.data[i]),
+				Diff:      Number(diff),
+			})
+		}
+	}
+
+	if compared := report.Count - report.NaN; compared > 0 {
+		report.MeanAbs = Number(sum / float64(compared))
+	}
+
+	slices.SortStableFunc(offenders, byDiff)
+
+	report.Worst = offenders[:min(worst, len(offenders))]
+
+	return report
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kachaje/hog/hog"
+)
+
+func TestCompare(t *testing.T) {
+	actual := tensor{shape: []int{2, 3}, data: []float64{1, 2, 3, 4, 5, 6}}
+	reference := tensor{shape: []int{2, 3}, data: []float64{1, 2.5, 3, 4, 5, 3}}
+
+	report := compare("pixels", actual, reference, 0.1, 1)
+
+	if report.Count != 6 || report.OverTolerance != 2 || report.MaxAbs != 3 || report.MeanAbs != 3.5/6 {
+		t.Fatalf("Test failed. Unexpected report %+v", report)
+	}
+
+	target := []Offender{{Index: []int{1, 2}, Go: 6, Reference: 3, Diff: 3}}
+
+	if !reflect.DeepEqual(report.Worst, target) {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", target, report.Worst)
+	}
+
+	report = compare("pixels", actual, tensor{shape: []int{3, 2}, data: reference.data}, 0.1, 1)
+
+	if report.Skipped == "" || report.Count != 0 {
+		t.Fatalf("Test failed. Expected mismatched shapes to be skipped; Actual: %+v", report)
+	}
+}
+
+func TestCompareNaN(t *testing.T) {
+	actual := tensor{shape: []int{4}, data: []float64{math.NaN(), 1, math.NaN(), math.Inf(1)}}
+	reference := tensor{shape: []int{4}, data: []float64{0, 1.5, 2, 0}}
+
+	report := compare("features", actual, reference, 0.1, 2)
+
+	if report.NaN != 2 || report.OverTolerance != 4 || !math.IsInf(float64(report.MaxAbs), 1) || !math.IsInf(float64(report.MeanAbs), 1) {
+		t.Fatalf("Test failed. Unexpected report %+v", report)
+	}
+
+	for _, offender := range report.Worst {
+		if !math.IsNaN(float64(offender.Diff)) {
+			t.Fatalf("Test failed. Expected the NaNs first; Actual: %v", report.Worst)
+		}
+	}
+
+	var out bytes.Buffer
+
+	if err := writeJSON(&out, []StageReport{report}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, target := range []string{`"go": "NaN"`, `"maxAbs": "+Inf"`, `"nan": 2`} {
+		if !strings.Contains(out.String(), target) {
+			t.Fatalf("Test failed. Expected %s in %s", target, out.String())
+		}
+	}
+
+	report = compare("features", actual, tensor{shape: []int{4}, data: []float64{0, 1, 2, math.Inf(1)}}, 0.1, 5)
+
+	if report.NaN != 3 || report.MeanAbs != 0 {
+		t.Fatalf("Test failed. Unexpected report %+v", report)
+	}
+}
+
+func TestReadTensor(t *testing.T) {
+	dir := t.TempDir()
+
+	for content, target := range map[string][]int{
+		"[[1, 2], [3, 4], [5, 6]]": {3, 2},
+		"[[[1], [2]], [[3], [4]]]": {2, 2, 1},
+		"[[1, 2], [3]]":            nil,
+		`[["a", "b"], ["c", "d"]]`: nil,
+	} {
+		path := filepath.Join(dir, "stage.json")
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := readTensor(path)
+
+		if target == nil {
+			if err == nil {
+				t.Fatalf("Test failed. Expected an error reading %s", content)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(result.shape, target) {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", target, result.shape)
+		}
+	}
+}
+
+func TestRunIsolated(t *testing.T) {
+	h, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err := run(h, "", filepath.Join("..", "..", "hog", "fixtures"), true, 1e-4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byStage := map[string]StageReport{}
+	for _, report := range reports {
+		byStage[report.Stage] = report
+	}
+
+	// Given the reference gradients, the histograms and blocks agree with the
+	// Python reference
+	for _, name := range []string{"hist", "features"} {
+		if report := byStage[name]; report.Count == 0 || report.OverTolerance != 0 {
+			t.Fatalf("Test failed. Expected %v to match; Actual: %+v", name, report)
+		}
+	}
+
+	var buffer bytes.Buffer
+
+	if err := writeText(&buffer, reports); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buffer.String(), "features.json") {
+		t.Fatalf("Test failed. Expected the text report to name the references; Actual: %v", buffer.String())
+	}
+
+	buffer.Reset()
+
+	if err := writeJSON(&buffer, reports); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded []StageReport
+
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(decoded) != len(stages) {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", len(stages), len(decoded))
+	}
+}
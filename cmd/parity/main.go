@@ -0,0 +1,330 @@
+// Command parity compares the stages of the Go pipeline with the reference
+// dumps written by py/app.py, to find the stage where the two diverge.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kachaje/hog/hog"
+)
+
+// stage pairs a Go stage with the reference files that may hold it, in order
+// of preference.
+type stage struct {
+	name  string
+	files []string
+}
+
+var stages = []stage{
+	{"pixels", []string{"dump.json"}},
+	{"magnitudes", []string{"magnitudes.json"}},
+	{"angles", []string{"thetas.json", "angles.json"}},
+	{"hist", []string{"hist.json"}},
+	{"features", []string{"features.json"}},
+}
+
+func main() {
+	var filename, refDir, format string
+	var tolerance float64
+	var worst int
+	var isolate bool
+
+	flag.StringVar(&filename, "f", "", "image to run the Go pipeline on")
+	flag.StringVar(&refDir, "ref", filepath.Join("hog", "fixtures"), "directory holding the reference dumps")
+	flag.Float64Var(&tolerance, "tol", 1e-6, "largest absolute difference not reported as an error")
+	flag.IntVar(&worst, "worst", 5, "number of worst-offending coordinates listed per stage")
+	flag.StringVar(&format, "format", "text", "report format: text or json")
+	flag.BoolVar(&isolate, "isolate", false, "compute every stage from the reference's previous stage instead of from the image")
+
+	flag.Parse()
+
+	if format != "text" && format != "json" {
+		log.Fatalf("Unknown format %q", format)
+	}
+
+	if worst < 0 {
+		log.Fatalf("Invalid -worst %d: must not be negative", worst)
+	}
+
+	if filename == "" && !isolate {
+		log.Fatal("Missing required filename, or -isolate")
+	}
+
+	h, err := hog.NewHOG()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	reports, err := run(h, filename, refDir, isolate, tolerance, worst)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if format == "json" {
+		err = writeJSON(os.Stdout, reports)
+	} else {
+		err = writeText(os.Stdout, reports)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run computes the Go stages and compares each with its reference. Stages
+// without a reference file are skipped.
+func run(h *hog.HOG, filename, refDir string, isolate bool, tolerance float64, worst int) ([]StageReport, error) {
+	references := map[string]tensor{}
+	paths := map[string]string{}
+
+	for _, s := range stages {
+		for _, name := range s.files {
+			path := filepath.Join(refDir, name)
+
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+
+			t, err := readTensor(path)
+			if err != nil {
+				return nil, err
+			}
+
+			references[s.name] = t
+			paths[s.name] = name
+
+			break
+		}
+	}
+
+	var actual map[string]tensor
+	var err error
+
+	if isolate {
+		actual, err = isolated(h, filename, references)
+	} else {
+		actual, err = endToEnd(h, filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reports := []StageReport{}
+
+	for _, s := range stages {
+		reference, ok := references[s.name]
+		if !ok {
+			reports = append(reports, StageReport{Stage: s.name, Skipped: "no reference dump", Worst: []Offender{}})
+			continue
+		}
+
+		result, ok := actual[s.name]
+		if !ok {
+			reports = append(reports, StageReport{Stage: s.name, Reference: paths[s.name], Skipped: "not computed", Worst: []Offender{}})
+			continue
+		}
+
+		report := compare(s.name, result, reference, tolerance, worst)
+		report.Reference = paths[s.name]
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+func loadImage(filename string) (image.Image, error) {
+	reader, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	img, _, err := image.Decode(reader)
+
+	return img, err
+}
+
+// endToEnd runs every stage from the image.
+func endToEnd(h *hog.HOG, filename string) (map[string]tensor, error) {
+	img, err := loadImage(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	trace, err := h.Trace(img)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]tensor{
+		"pixels":     planeTensor(trace.Pixels),
+		"magnitudes": planeTensor(trace.Magnitude),
+		"angles":     planeTensor(trace.Angle),
+		"hist":       gridTensor(trace.Histogram),
+		"features":   gridTensor(trace.Blocks),
+	}, nil
+}
+
+// isolated computes every stage from the reference of the stage before it,
+// so a divergence shows up only in the stage that causes it. Pixels are only
+// compared when an image is given.
+func isolated(h *hog.HOG, filename string, references map[string]tensor) (map[string]tensor, error) {
+	actual := map[string]tensor{}
+
+	if filename != "" {
+		img, err := loadImage(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		trace, err := h.Trace(img)
+		if err != nil {
+			return nil, err
+		}
+
+		actual["pixels"] = planeTensor(trace.Pixels)
+	}
+
+	if pixels, ok := references["pixels"]; ok {
+		trace := &hog.Trace{}
+
+		if trace.Pixels, ok = pixels.plane(); ok {
+			if err := h.Resume(hog.StagePixels, trace); err != nil {
+				return nil, err
+			}
+
+			actual["magnitudes"] = planeTensor(trace.Magnitude)
+			actual["angles"] = planeTensor(trace.Angle)
+		}
+	}
+
+	magnitudes, hasMagnitudes := references["magnitudes"]
+	angles, hasAngles := references["angles"]
+
+	if hasMagnitudes && hasAngles {
+		trace := &hog.Trace{}
+
+		var okMagnitudes, okAngles bool
+
+		trace.Magnitude, okMagnitudes = magnitudes.plane()
+		trace.Angle, okAngles = angles.plane()
+
+		if okMagnitudes && okAngles {
+			if err := h.Resume(hog.StageGradients, trace); err != nil {
+				return nil, err
+			}
+
+			actual["hist"] = gridTensor(trace.Histogram)
+		}
+	}
+
+	if hist, ok := references["hist"]; ok {
+		trace := &hog.Trace{}
+
+		if trace.Histogram, ok = hist.grid(); ok {
+			if err := h.Resume(hog.StageHistogram, trace); err != nil {
+				return nil, err
+			}
+
+			actual["features"] = gridTensor(trace.Blocks)
+		}
+	}
+
+	return actual, nil
+}
+
+// plane converts a two-dimensional tensor to a Plane.
+func (t tensor) plane() (hog.Plane, bool) {
+	if len(t.shape) != 2 {
+		return hog.Plane{}, false
+	}
+
+	p := hog.NewPlane(t.shape[1], t.shape[0])
+
+	for i, value := range t.data {
+		p.Data[i] = float32(value)
+	}
+
+	return p, true
+}
+
+// grid converts a three-dimensional tensor to a Grid.
+func (t tensor) grid() (hog.Grid, bool) {
+	if len(t.shape) != 3 {
+		return hog.Grid{}, false
+	}
+
+	g := hog.NewGrid(t.shape[0], t.shape[1], t.shape[2])
+
+	for i, value := range t.data {
+		g.Data[i] = float32(value)
+	}
+
+	return g, true
+}
+
+func writeJSON(w io.Writer, reports []StageReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(reports)
+}
+
+func writeText(w io.Writer, reports []StageReport) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(table, "STAGE\tREFERENCE\tELEMENTS\tNAN\tMAX-ABS\tMEAN-ABS\tOVER-TOL")
+
+	for _, report := range reports {
+		if report.Skipped != "" {
+			note := report.Skipped
+			if report.ReferenceShape != nil {
+				note = fmt.Sprintf("%s: %v vs %v", note, shape(report.Shape), shape(report.ReferenceShape))
+			}
+
+			fmt.Fprintf(table, "%s\t%s\t-\t-\t-\t-\t%s\n", report.Stage, report.Reference, note)
+			continue
+		}
+
+		fmt.Fprintf(table, "%s\t%s\t%d\t%d\t%.6g\t%.6g\t%d\n", report.Stage, report.Reference, report.Count, report.NaN, report.MaxAbs, report.MeanAbs, report.OverTolerance)
+	}
+
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		if len(report.Worst) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "\n%s worst:\n", report.Stage)
+
+		for _, offender := range report.Worst {
+			fmt.Fprintf(w, "  %v go=%.9g reference=%.9g diff=%.3g\n", offender.Index, offender.Go, offender.Reference, offender.Diff)
+		}
+	}
+
+	return nil
+}
+
+func shape(dims []int) string {
+	parts := make([]string, len(dims))
+
+	for i, dim := range dims {
+		parts[i] = fmt.Sprint(dim)
+	}
+
+	return strings.Join(parts, "x")
+}
@@ -3,6 +3,7 @@
 if [[ "$1" == "-c" ]]; then
 
   rm -f **/**/output* **/output* output*
+  rm -rf run-*
 
 elif [[ "$1" == "-b" ]]; then
 
@@ -0,0 +1,173 @@
+package hog
+
+import (
+	"context"
+	"image"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Source provides one image for batch extraction. Images are opened by the
+// worker that processes them, so only a handful are decoded at any time.
+type Source interface {
+	Open() (image.Image, error)
+}
+
+// FileSource decodes the image file at the given path.
+type FileSource string
+
+func (s FileSource) Open() (image.Image, error) {
+	reader, err := os.Open(string(s))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	img, _, err := image.Decode(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return img, nil
+}
+
+// SourceFunc adapts a function to the Source interface.
+type SourceFunc func() (image.Image, error)
+
+func (s SourceFunc) Open() (image.Image, error) {
+	return s()
+}
+
+// FileSources returns a FileSource for every path.
+func FileSources(paths ...string) []Source {
+	sources := make([]Source, len(paths))
+
+	for i, path := range paths {
+		sources[i] = FileSource(path)
+	}
+
+	return sources
+}
+
+// DirSources returns a FileSource for every JPEG and PNG file directly inside
+// dir, sorted by name.
+func DirSources(dir string) ([]Source, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".jpg", ".jpeg", ".png":
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	slices.Sort(paths)
+
+	return FileSources(paths...), nil
+}
+
+type BatchResult struct {
+	Features []float32
+	Err      error
+}
+
+type BatchOptions struct {
+	// Workers is the number of images processed concurrently. It defaults to
+	// the number of CPUs.
+	Workers int
+	// Progress, if set, is called after every item with the number of items
+	// finished so far. Calls never overlap.
+	Progress func(done, total int)
+}
+
+// Batch extracts the features of every source using a bounded pool of
+// workers. Results are in the same order as sources and carry their own
+// error. If ctx is cancelled, the items that were not processed report the
+// context's error, which is also returned.
+func (f *HOG) Batch(ctx context.Context, sources []Source, opts BatchOptions) ([]BatchResult, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, len(sources))
+
+	results := make([]BatchResult, len(sources))
+
+	indices := make(chan int)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+
+	for range workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indices {
+				results[i] = f.extract(ctx, sources[i])
+
+				if opts.Progress != nil {
+					mu.Lock()
+					done++
+					opts.Progress(done, len(sources))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	next := 0
+
+feed:
+	for ; next < len(sources); next++ {
+		select {
+		case <-ctx.Done():
+			break feed
+		case indices <- next:
+		}
+	}
+
+	close(indices)
+
+	wg.Wait()
+
+	for i := next; i < len(sources); i++ {
+		results[i].Err = ctx.Err()
+	}
+
+	return results, ctx.Err()
+}
+
+func (f *HOG) extract(ctx context.Context, source Source) BatchResult {
+	if err := ctx.Err(); err != nil {
+		return BatchResult{Err: err}
+	}
+
+	img, err := source.Open()
+	if err != nil {
+		return BatchResult{Err: err}
+	}
+
+	_, features, err := f.HOG(img, nil)
+	if err != nil {
+		return BatchResult{Err: err}
+	}
+
+	return BatchResult{Features: features}
+}
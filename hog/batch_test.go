@@ -0,0 +1,154 @@
+package hog_test
+
+import (
+	"context"
+	"errors"
+	"image"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kachaje/hog/hog"
+)
+
+func TestBatch(t *testing.T) {
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := []string{
+		filepath.Join("..", "data", "flower.jpg"),
+		filepath.Join("..", "data", "face.jpg"),
+		filepath.Join("..", "data", "missing.jpg"),
+		filepath.Join("..", "data", "fhog.png"),
+		filepath.Join("..", "data", "thumbnail.png"),
+		filepath.Join("..", "data", "flowerGray.jpg"),
+	}
+
+	failure := errors.New("broken source")
+
+	sources := append(hog.FileSources(paths...), hog.SourceFunc(func() (image.Image, error) {
+		return nil, failure
+	}))
+
+	var calls atomic.Int32
+	lastDone := 0
+
+	results, err := f.Batch(context.Background(), sources, hog.BatchOptions{
+		Workers: 3,
+		Progress: func(done, total int) {
+			calls.Add(1)
+
+			if total != len(sources) || done != lastDone+1 {
+				t.Errorf("Test failed. Unexpected progress %v/%v", done, total)
+			}
+
+			lastDone = done
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != len(sources) {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", len(sources), len(results))
+	}
+
+	if int(calls.Load()) != len(sources) {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", len(sources), calls.Load())
+	}
+
+	if results[2].Err == nil {
+		t.Fatal("Test failed. Expected an error for a missing file")
+	}
+
+	if !errors.Is(results[6].Err, failure) {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", failure, results[6].Err)
+	}
+
+	for i, path := range paths {
+		if i == 2 {
+			continue
+		}
+
+		if results[i].Err != nil {
+			t.Fatal(results[i].Err)
+		}
+
+		img, err := hog.FileSource(path).Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, target, err := f.HOG(img, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for j := range target {
+			if results[i].Features[j] != target[j] {
+				t.Fatalf("Test failed on %v. Expected: %v; Actual: %v", path, target[j], results[i].Features[j])
+			}
+		}
+	}
+}
+
+func TestBatchCancel(t *testing.T) {
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sources := make([]hog.Source, 20)
+	for i := range sources {
+		sources[i] = hog.SourceFunc(func() (image.Image, error) {
+			// The first image cancels the rest of the batch
+			cancel()
+
+			return image.NewGray(image.Rect(0, 0, 64, 128)), nil
+		})
+	}
+
+	results, err := f.Batch(ctx, sources, hog.BatchOptions{Workers: 1})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", context.Canceled, err)
+	}
+
+	if results[0].Err != nil || len(results[0].Features) != 3780 {
+		t.Fatalf("Test failed. Expected the first item to finish; Actual: %v", results[0].Err)
+	}
+
+	for _, result := range results[1:] {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", context.Canceled, result.Err)
+		}
+	}
+}
+
+func TestDirSources(t *testing.T) {
+	sources, err := hog.DirSources(filepath.Join("..", "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := []hog.Source{
+		hog.FileSource(filepath.Join("..", "data", "face.jpg")),
+		hog.FileSource(filepath.Join("..", "data", "fhog.png")),
+		hog.FileSource(filepath.Join("..", "data", "flower.jpg")),
+		hog.FileSource(filepath.Join("..", "data", "flowerGray.jpg")),
+		hog.FileSource(filepath.Join("..", "data", "thumbnail.png")),
+	}
+
+	if !reflect.DeepEqual(sources, target) {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", target, sources)
+	}
+
+	if _, err := hog.DirSources(filepath.Join("..", "data", "missing")); err == nil {
+		t.Fatal("Test failed. Expected an error for a missing directory")
+	}
+}
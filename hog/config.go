@@ -0,0 +1,430 @@
+package hog
+
+import (
+	"fmt"
+	"image/color"
+
+	"golang.org/x/image/draw"
+)
+
+type BlockNorm int
+
+const (
+	NormL2 BlockNorm = iota
+	NormL2Hys
+	NormL1
+	NormL1Sqrt
+)
+
+type Compression int
+
+const (
+	CompressionNone Compression = iota
+	CompressionGamma
+	CompressionSqrt
+	CompressionLog
+)
+
+type Resampler int
+
+const (
+	ResampleNearest Resampler = iota
+	ResampleApproxBiLinear
+	ResampleBiLinear
+	ResampleCatmullRom
+)
+
+func (r Resampler) interpolator() draw.Interpolator {
+	switch r {
+	case ResampleApproxBiLinear:
+		return draw.ApproxBiLinear
+	case ResampleBiLinear:
+		return draw.BiLinear
+	case ResampleCatmullRom:
+		return draw.CatmullRom
+	default:
+		return draw.NearestNeighbor
+	}
+}
+
+type FitMode int
+
+const (
+	// FitStretch scales the input to the window, ignoring its aspect ratio
+	FitStretch FitMode = iota
+	// FitCrop scales the input to cover the window and crops the overflow
+	FitCrop
+	// FitLetterbox scales the input to fit inside the window and pads the
+	// remainder with a solid colour
+	FitLetterbox
+	// FitReplicate is FitLetterbox with the padding filled by repeating the
+	// outermost pixels of the input
+	FitReplicate
+)
+
+// Semantics selects the algorithm behind the pixel, gradient, binning and L2
+// normalization stages.
+type Semantics int
+
+const (
+	// SemanticsLegacy reproduces py/app.py: gray levels divided by 257,
+	// gradients with its edge rules, interpolated votes and L2 as x/(k+eps).
+	SemanticsLegacy Semantics = iota
+	// SemanticsSkimage reproduces skimage.feature.hog: gray levels in [0, 1]
+	// with rgb2gray weights, central differences that are zero on the image
+	// border, unsigned angles binned without interpolation, cell histograms
+	// averaged over the cell and L2 as x/sqrt(k²+eps²). Gray levels and
+	// gradients are float64 up to the binning, like numpy's, so angles on a
+	// bin edge, such as 45 degrees with 4 or 12 bins, land in skimage's bin.
+	SemanticsSkimage
+)
+
+// Names of the enum values in text and JSON, such as model files. They are
+// keyed by value so adding or reordering constants never renames one.
+var (
+	blockNormNames = map[BlockNorm]string{
+		NormL2:     "l2",
+		NormL2Hys:  "l2-hys",
+		NormL1:     "l1",
+		NormL1Sqrt: "l1-sqrt",
+	}
+	compressionNames = map[Compression]string{
+		CompressionNone:  "none",
+		CompressionGamma: "gamma",
+		CompressionSqrt:  "sqrt",
+		CompressionLog:   "log",
+	}
+	resamplerNames = map[Resampler]string{
+		ResampleNearest:        "nearest",
+		ResampleApproxBiLinear: "approx-bilinear",
+		ResampleBiLinear:       "bilinear",
+		ResampleCatmullRom:     "catmull-rom",
+	}
+	fitNames = map[FitMode]string{
+		FitStretch:   "stretch",
+		FitCrop:      "crop",
+		FitLetterbox: "letterbox",
+		FitReplicate: "replicate",
+	}
+	semanticsNames = map[Semantics]string{
+		SemanticsLegacy:  "legacy",
+		SemanticsSkimage: "skimage",
+	}
+)
+
+func enumString[T ~int](value T, names map[T]string, kind string) string {
+	if name, ok := names[value]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("%s(%d)", kind, int(value))
+}
+
+func marshalEnum[T ~int](value T, names map[T]string, kind string) ([]byte, error) {
+	name, ok := names[value]
+	if !ok {
+		return nil, fmt.Errorf("unknown %s %d", kind, int(value))
+	}
+
+	return []byte(name), nil
+}
+
+func unmarshalEnum[T ~int](text []byte, names map[T]string, kind string, value *T) error {
+	for candidate, name := range names {
+		if name == string(text) {
+			*value = candidate
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown %s %q", kind, text)
+}
+
+func (n BlockNorm) String() string { return enumString(n, blockNormNames, "BlockNorm") }
+
+func (n BlockNorm) MarshalText() ([]byte, error) {
+	return marshalEnum(n, blockNormNames, "block norm")
+}
+
+func (n *BlockNorm) UnmarshalText(text []byte) error {
+	return unmarshalEnum(text, blockNormNames, "block norm", n)
+}
+
+func (c Compression) String() string { return enumString(c, compressionNames, "Compression") }
+
+func (c Compression) MarshalText() ([]byte, error) {
+	return marshalEnum(c, compressionNames, "compression")
+}
+
+func (c *Compression) UnmarshalText(text []byte) error {
+	return unmarshalEnum(text, compressionNames, "compression", c)
+}
+
+func (r Resampler) String() string { return enumString(r, resamplerNames, "Resampler") }
+
+func (r Resampler) MarshalText() ([]byte, error) {
+	return marshalEnum(r, resamplerNames, "resampler")
+}
+
+func (r *Resampler) UnmarshalText(text []byte) error {
+	return unmarshalEnum(text, resamplerNames, "resampler", r)
+}
+
+func (m FitMode) String() string { return enumString(m, fitNames, "FitMode") }
+
+func (m FitMode) MarshalText() ([]byte, error) {
+	return marshalEnum(m, fitNames, "fit mode")
+}
+
+func (m *FitMode) UnmarshalText(text []byte) error {
+	return unmarshalEnum(text, fitNames, "fit mode", m)
+}
+
+func (s Semantics) String() string { return enumString(s, semanticsNames, "Semantics") }
+
+func (s Semantics) MarshalText() ([]byte, error) {
+	return marshalEnum(s, semanticsNames, "semantics")
+}
+
+func (s *Semantics) UnmarshalText(text []byte) error {
+	return unmarshalEnum(text, semanticsNames, "semantics", s)
+}
+
+// Config records every setting that affects the descriptor, so features
+// computed with different settings can be told apart. Enums are written to
+// JSON by name.
+type Config struct {
+	Bins                 int         `json:"bins"`
+	Epsilon              float64     `json:"epsilon"`
+	Width                int         `json:"width"`
+	Height               int         `json:"height"`
+	CellSize             int         `json:"cellSize"`
+	BlockSize            int         `json:"blockSize"`
+	BlockStride          int         `json:"blockStride"`
+	BlockNorm            BlockNorm   `json:"blockNorm"`
+	Signed               bool        `json:"signed"`
+	SpatialInterpolation bool        `json:"spatialInterpolation"`
+	GaussianWeighting    bool        `json:"gaussianWeighting"`
+	Compression          Compression `json:"compression"`
+	Gamma                float64     `json:"gamma"`
+	Colour               bool        `json:"colour"`
+	Resampler            Resampler   `json:"resampler"`
+	Fit                  FitMode     `json:"fit"`
+	Pad                  color.RGBA  `json:"pad"`
+	Semantics            Semantics   `json:"semantics"`
+}
+
+// Option adjusts one setting of the Config an extractor is built from.
+type Option func(*Config)
+
+// DefaultConfig returns the Dalal-Triggs pedestrian settings: a 64x128
+// window, 8x8 cells, 2x2 blocks with a stride of one cell and 9 unsigned bins.
+func DefaultConfig() Config {
+	return Config{
+		Bins:        9,
+		Epsilon:     1e-05,
+		Width:       64,
+		Height:      128,
+		CellSize:    8,
+		BlockSize:   2,
+		BlockStride: 1,
+	}
+}
+
+func (c Config) Validate() error {
+	orientationRange := 180
+	if c.Signed {
+		orientationRange = 360
+	}
+
+	if c.Bins <= 0 {
+		return fmt.Errorf("number of bins must be positive, got %d", c.Bins)
+	}
+
+	if orientationRange%c.Bins != 0 {
+		return fmt.Errorf("%d is not divisible by the number of bins %d", orientationRange, c.Bins)
+	}
+
+	if c.Epsilon <= 0 {
+		return fmt.Errorf("epsilon must be positive, got %v", c.Epsilon)
+	}
+
+	if c.Width <= 0 || c.Height <= 0 {
+		return fmt.Errorf("window must have a positive size, got %dx%d", c.Width, c.Height)
+	}
+
+	if c.CellSize <= 0 {
+		return fmt.Errorf("cell size must be positive, got %d", c.CellSize)
+	}
+
+	if c.BlockSize <= 0 || c.BlockStride <= 0 {
+		return fmt.Errorf("block size and stride must be positive, got %d and %d", c.BlockSize, c.BlockStride)
+	}
+
+	if c.Width/c.CellSize < c.BlockSize || c.Height/c.CellSize < c.BlockSize {
+		return fmt.Errorf("a %dx%d window does not fit a block of %d cells of %d pixels", c.Width, c.Height, c.BlockSize, c.CellSize)
+	}
+
+	if c.BlockNorm < NormL2 || c.BlockNorm > NormL1Sqrt {
+		return fmt.Errorf("unknown block normalization %d", c.BlockNorm)
+	}
+
+	if c.Compression < CompressionNone || c.Compression > CompressionLog {
+		return fmt.Errorf("unknown compression %d", c.Compression)
+	}
+
+	if c.Compression == CompressionGamma && c.Gamma <= 0 {
+		return fmt.Errorf("gamma must be positive, got %v", c.Gamma)
+	}
+
+	if c.Resampler < ResampleNearest || c.Resampler > ResampleCatmullRom {
+		return fmt.Errorf("unknown resampler %d", c.Resampler)
+	}
+
+	if c.Fit < FitStretch || c.Fit > FitReplicate {
+		return fmt.Errorf("unknown fit mode %d", c.Fit)
+	}
+
+	if c.Semantics < SemanticsLegacy || c.Semantics > SemanticsSkimage {
+		return fmt.Errorf("unknown semantics %d", c.Semantics)
+	}
+
+	if c.Semantics == SemanticsSkimage && (c.Signed || c.SpatialInterpolation || c.GaussianWeighting) {
+		return fmt.Errorf("skimage semantics support neither signed orientations, spatial interpolation nor gaussian weighting")
+	}
+
+	return nil
+}
+
+// WithBins sets the number of orientation bins.
+func WithBins(bins int) Option {
+	return func(c *Config) {
+		c.Bins = bins
+	}
+}
+
+// WithEpsilon sets the constant that keeps block normalization finite.
+func WithEpsilon(epsilon float64) Option {
+	return func(c *Config) {
+		c.Epsilon = epsilon
+	}
+}
+
+// WithWindow sets the detection window every input image is resized to.
+func WithWindow(width, height int) Option {
+	return func(c *Config) {
+		c.Width = width
+		c.Height = height
+	}
+}
+
+// WithCellSize sets the number of pixels along each side of a cell.
+func WithCellSize(pixels int) Option {
+	return func(c *Config) {
+		c.CellSize = pixels
+	}
+}
+
+// WithBlock sets the number of cells along each side of a normalization
+// block and the number of cells the block moves by between positions.
+func WithBlock(cells, stride int) Option {
+	return func(c *Config) {
+		c.BlockSize = cells
+		c.BlockStride = stride
+	}
+}
+
+// WithBlockNorm sets the scheme used to normalize each block.
+func WithBlockNorm(norm BlockNorm) Option {
+	return func(c *Config) {
+		c.BlockNorm = norm
+	}
+}
+
+// WithSigned switches between unsigned (0-180 degrees) and signed (0-360
+// degrees) gradient orientations.
+func WithSigned(signed bool) Option {
+	return func(c *Config) {
+		c.Signed = signed
+	}
+}
+
+// WithSpatialInterpolation enables Dalal-Triggs trilinear voting, where a
+// pixel's vote is shared with the neighbouring cells. It also changes who
+// votes: without it only the last pixel of each cell votes, as in py/app.py,
+// while with it every pixel does, so an interior cell of uniform gradients
+// holds cellSize² times the votes.
+func WithSpatialInterpolation(enabled bool) Option {
+	return func(c *Config) {
+		c.SpatialInterpolation = enabled
+	}
+}
+
+// WithGaussianWeighting enables weighting every pixel's vote by a Gaussian
+// window centred on the block, with sigma equal to half the block width.
+func WithGaussianWeighting(enabled bool) Option {
+	return func(c *Config) {
+		c.GaussianWeighting = enabled
+	}
+}
+
+// WithCompression sets the intensity compression applied before gradients
+// are computed. gamma is only used by CompressionGamma.
+func WithCompression(compression Compression, gamma float64) Option {
+	return func(c *Config) {
+		c.Compression = compression
+		c.Gamma = gamma
+	}
+}
+
+// WithColour enables colour gradients, where every pixel keeps the RGB
+// channel with the largest gradient magnitude.
+func WithColour(enabled bool) Option {
+	return func(c *Config) {
+		c.Colour = enabled
+	}
+}
+
+// WithResampler sets the kernel used to resize inputs to the window.
+func WithResampler(resampler Resampler) Option {
+	return func(c *Config) {
+		c.Resampler = resampler
+	}
+}
+
+// WithFit sets how inputs whose aspect ratio differs from the window's are
+// mapped onto it. pad is only used by FitLetterbox.
+func WithFit(mode FitMode, pad color.Color) Option {
+	return func(c *Config) {
+		c.Fit = mode
+
+		if pad != nil {
+			c.Pad = color.RGBAModel.Convert(pad).(color.RGBA)
+		}
+	}
+}
+
+// WithSemantics sets the algorithm the pipeline stages follow.
+func WithSemantics(semantics Semantics) Option {
+	return func(c *Config) {
+		c.Semantics = semantics
+	}
+}
+
+// WithSkimage applies the defaults of skimage.feature.hog: 9 orientations,
+// 8x8 pixels per cell, 3x3 cells per block and L2-Hys normalization, with
+// skimage's semantics. skimage does not resize its input, so the window
+// should be set to the size of the images. Options given after it override
+// the defaults, like skimage's keyword arguments; transform_sqrt=True is
+// WithCompression(CompressionSqrt, 0).
+func WithSkimage() Option {
+	return func(c *Config) {
+		c.Bins = 9
+		c.CellSize = 8
+		c.BlockSize = 3
+		c.BlockStride = 1
+		c.BlockNorm = NormL2Hys
+		c.Semantics = SemanticsSkimage
+	}
+}
@@ -0,0 +1,183 @@
+package hog_test
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/kachaje/hog/hog"
+)
+
+func TestNewHOGDefaults(t *testing.T) {
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := f.Config()
+
+	if config != hog.DefaultConfig() {
+		t.Fatalf("Test failed. Expected: %#v; Actual: %#v", hog.DefaultConfig(), config)
+	}
+
+	if f.FeatureLength() != 3780 {
+		t.Fatalf("Test failed. Expected: 3780; Actual: %v", f.FeatureLength())
+	}
+}
+
+func TestNewHOGOptions(t *testing.T) {
+	pad := color.RGBA{10, 20, 30, 255}
+
+	f, err := hog.NewHOG(
+		hog.WithBins(12),
+		hog.WithEpsilon(1e-3),
+		hog.WithWindow(32, 48),
+		hog.WithCellSize(4),
+		hog.WithBlock(3, 2),
+		hog.WithBlockNorm(hog.NormL2Hys),
+		hog.WithSigned(true),
+		hog.WithSpatialInterpolation(true),
+		hog.WithGaussianWeighting(true),
+		hog.WithCompression(hog.CompressionGamma, 0.5),
+		hog.WithColour(true),
+		hog.WithResampler(hog.ResampleCatmullRom),
+		hog.WithFit(hog.FitLetterbox, pad),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := hog.Config{
+		Bins:                 12,
+		Epsilon:              1e-3,
+		Width:                32,
+		Height:               48,
+		CellSize:             4,
+		BlockSize:            3,
+		BlockStride:          2,
+		BlockNorm:            hog.NormL2Hys,
+		Signed:               true,
+		SpatialInterpolation: true,
+		GaussianWeighting:    true,
+		Compression:          hog.CompressionGamma,
+		Gamma:                0.5,
+		Colour:               true,
+		Resampler:            hog.ResampleCatmullRom,
+		Fit:                  hog.FitLetterbox,
+		Pad:                  pad,
+	}
+
+	if f.Config() != target {
+		t.Fatalf("Test failed. Expected: %#v; Actual: %#v", target, f.Config())
+	}
+
+	g, err := hog.FromConfig(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g.Config() != target {
+		t.Fatalf("Test failed. Expected: %#v; Actual: %#v", target, g.Config())
+	}
+}
+
+func TestNewHOGValidation(t *testing.T) {
+	targets := map[string][]hog.Option{
+		"zero bins":          {hog.WithBins(0)},
+		"indivisible bins":   {hog.WithBins(7)},
+		"indivisible signed": {hog.WithBins(7), hog.WithSigned(true)},
+		"zero epsilon":       {hog.WithEpsilon(0)},
+		"empty window":       {hog.WithWindow(0, 128)},
+		"zero cell":          {hog.WithCellSize(0)},
+		"zero block":         {hog.WithBlock(0, 1)},
+		"zero stride":        {hog.WithBlock(2, 0)},
+		"block too large":    {hog.WithWindow(16, 16), hog.WithBlock(3, 1)},
+		"unknown norm":       {hog.WithBlockNorm(hog.BlockNorm(42))},
+		"unknown resampler":  {hog.WithResampler(hog.Resampler(-1))},
+		"zero gamma":         {hog.WithCompression(hog.CompressionGamma, 0)},
+		"unknown fit":        {hog.WithFit(hog.FitMode(9), nil)},
+	}
+
+	for name, opts := range targets {
+		if _, err := hog.NewHOG(opts...); err == nil {
+			t.Fatalf("Test failed on %s. Expected an error", name)
+		}
+	}
+
+	// 360 degrees split into 24 bins is fine even though 180 is not
+	if _, err := hog.NewHOG(hog.WithBins(24), hog.WithSigned(true)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewHOGTinyWindows(t *testing.T) {
+	for _, size := range []image.Point{{1, 1}, {2, 2}, {1, 5}, {5, 2}} {
+		f, err := hog.NewHOG(hog.WithWindow(size.X, size.Y), hog.WithCellSize(1), hog.WithBlock(1, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		img := image.NewGray(image.Rect(0, 0, size.X, size.Y))
+		for i := range img.Pix {
+			img.Pix[i] = uint8(40 * (i + 1))
+		}
+
+		_, features, err := f.HOG(img, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(features) != f.FeatureLength() {
+			t.Fatalf("Test failed on %v. Expected: %v; Actual: %v", size, f.FeatureLength(), len(features))
+		}
+	}
+}
+
+func TestConfigJSON(t *testing.T) {
+	f, err := hog.NewHOG(
+		hog.WithBlockNorm(hog.NormL1Sqrt),
+		hog.WithCompression(hog.CompressionLog, 0),
+		hog.WithResampler(hog.ResampleCatmullRom),
+		hog.WithFit(hog.FitReplicate, nil),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(f.Config())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, target := range []string{`"blockNorm":"l1-sqrt"`, `"compression":"log"`, `"resampler":"catmull-rom"`, `"fit":"replicate"`, `"semantics":"legacy"`, `"cellSize":8`} {
+		if !strings.Contains(string(data), target) {
+			t.Fatalf("Test failed. Expected %s in %s", target, data)
+		}
+	}
+
+	var config hog.Config
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+
+	if config != f.Config() {
+		t.Fatalf("Test failed. Expected: %#v; Actual: %#v", f.Config(), config)
+	}
+
+	for _, content := range []string{`{"blockNorm":"L2"}`, `{"compression":2}`, `{"fit":"stretched"}`, `{"semantics":""}`} {
+		if err := json.Unmarshal([]byte(content), &config); err == nil {
+			t.Fatalf("Test failed. Expected an error decoding %s", content)
+		}
+	}
+
+	if _, err := json.Marshal(hog.Config{BlockNorm: hog.BlockNorm(9)}); err == nil {
+		t.Fatal("Test failed. Expected an error encoding an unknown block norm")
+	}
+
+	if name := hog.Semantics(5).String(); name != "Semantics(5)" {
+		t.Fatalf("Test failed. Expected: Semantics(5); Actual: %v", name)
+	}
+}
@@ -0,0 +1,107 @@
+package hog
+
+import (
+	"fmt"
+	"image"
+)
+
+// Window is the descriptor of one window position in a dense scan. X and Y
+// are the window's top-left corner in the scanned image.
+type Window struct {
+	X        int
+	Y        int
+	Features []float32
+}
+
+// Dense computes the descriptor of every window position in img, moving the
+// window by stride pixels at a time. The image is not resized: gradients,
+// cell histograms and normalized blocks are computed once for the whole
+// image and every window reuses the blocks it covers. stride must be a
+// multiple of the distance between two blocks.
+//
+// A window's descriptor is not the one HOG computes on the same crop: inside
+// the image, the gradients on the window's border see the neighbouring
+// pixels, which HOG treats as zero, and with spatial interpolation the border
+// cells also receive votes from outside the window.
+func (f *HOG) Dense(img image.Image, stride int) ([]Window, error) {
+	step := f.cellSize * f.blockStride
+
+	if stride <= 0 || stride%step != 0 {
+		return nil, fmt.Errorf("window stride %d is not a positive multiple of the block stride of %d pixels", stride, step)
+	}
+
+	return f.Windows(f.DenseBlocks(img), stride), nil
+}
+
+// DenseBlocks computes the normalized blocks of the whole of img.
+func (f *HOG) DenseBlocks(img image.Image) Grid {
+	var gradients Gradients
+
+	switch {
+	case f.semantics == SemanticsSkimage:
+		gradients = f.skimageGradients(f.skimageChannels(img))
+	case f.colour:
+		channels := f.Channels(img)
+
+		for _, channel := range channels {
+			f.CompressPlane(channel)
+		}
+
+		gradients = f.ColourGradients(channels)
+	default:
+		pixels := f.Pixels(img)
+
+		f.CompressPlane(pixels)
+
+		gradients = f.Gradients(pixels)
+	}
+
+	if f.gaussian {
+		return f.WeightedBlocks(gradients)
+	}
+
+	return f.Blocks(f.Histogram(gradients))
+}
+
+// Windows slices the descriptors of every window position out of a grid of
+// blocks computed by DenseBlocks, moving the window by stride pixels, which
+// must be a multiple of the distance between two blocks. All descriptors
+// share one allocation.
+func (f *HOG) Windows(blocks Grid, stride int) []Window {
+	step := f.cellSize * f.blockStride
+	blockStep := stride / step
+
+	rows, cols := f.blockCount(f.height/f.cellSize, f.width/f.cellSize)
+
+	if blocks.Rows < rows || blocks.Cols < cols {
+		return nil
+	}
+
+	positionsY := (blocks.Rows-rows)/blockStep + 1
+	positionsX := (blocks.Cols-cols)/blockStep + 1
+
+	length := rows * cols * blocks.Depth
+
+	data := make([]float32, positionsY*positionsX*length)
+	windows := make([]Window, 0, positionsY*positionsX)
+
+	for i := range positionsY {
+		for j := range positionsX {
+			features := data[len(windows)*length : (len(windows)+1)*length]
+
+			window := blocks.SubGrid(i*blockStep, j*blockStep, rows, cols)
+
+			for k := range rows {
+				copy(features[k*cols*blocks.Depth:], window.Row(k))
+			}
+
+			windows = append(windows, Window{
+				X:        j * stride,
+				Y:        i * stride,
+				Features: features,
+			})
+		}
+	}
+
+	return windows
+}
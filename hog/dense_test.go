@@ -0,0 +1,144 @@
+package hog_test
+
+import (
+	"image"
+	"image/draw"
+	"path/filepath"
+	"testing"
+
+	"github.com/kachaje/hog/hog"
+)
+
+func loadRGBA(t *testing.T, name string, width, height int) *image.RGBA {
+	t.Helper()
+
+	img, err := hog.FileSource(filepath.Join("..", "data", name)).Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := hog.NewHOG(hog.WithWindow(width, height))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	draw.Draw(rgba, rgba.Rect, f.Resize(img, width, height), image.Point{}, draw.Src)
+
+	return rgba
+}
+
+func TestDenseSingleWindow(t *testing.T) {
+	img := loadRGBA(t, "flower.jpg", 64, 128)
+
+	for _, opts := range [][]hog.Option{
+		{},
+		{hog.WithSpatialInterpolation(true)},
+		{hog.WithGaussianWeighting(true), hog.WithColour(true)},
+	} {
+		f, err := hog.NewHOG(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		windows, err := f.Dense(img, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(windows) != 1 {
+			t.Fatalf("Test failed. Expected: 1; Actual: %v", len(windows))
+		}
+
+		_, target, err := f.HOG(img, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := range target {
+			if windows[0].Features[i] != target[i] {
+				t.Fatalf("Test failed. Expected: %v; Actual: %v", target[i], windows[0].Features[i])
+			}
+		}
+	}
+}
+
+func TestDense(t *testing.T) {
+	img := loadRGBA(t, "face.jpg", 128, 200)
+
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.Dense(img, 12); err == nil {
+		t.Fatal("Test failed. Expected an error for a stride that is not a multiple of 8")
+	}
+
+	windows, err := f.Dense(img, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 5 positions across and 5 down, ignoring the partial cell at the bottom
+	if len(windows) != 25 {
+		t.Fatalf("Test failed. Expected: 25; Actual: %v", len(windows))
+	}
+
+	last := windows[len(windows)-1]
+	if last.X != 64 || last.Y != 64 {
+		t.Fatalf("Test failed. Expected: 64, 64; Actual: %v, %v", last.X, last.Y)
+	}
+
+	blockLength := 36
+	blocksPerRow := 7
+
+	for _, window := range windows {
+		if len(window.Features) != f.FeatureLength() {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", f.FeatureLength(), len(window.Features))
+		}
+	}
+
+	// Neighbouring windows share blocks: the window two blocks to the right
+	// starts with the third block of the first window
+	first, second := windows[0].Features, windows[1].Features
+
+	for i := range blockLength {
+		if first[2*blockLength+i] != second[i] {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", first[2*blockLength+i], second[i])
+		}
+	}
+
+	// and the window below starts with the first block of the first window's
+	// third row of blocks
+	below := windows[5].Features
+
+	for i := range blockLength {
+		if first[2*blocksPerRow*blockLength+i] != below[i] {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", first[2*blocksPerRow*blockLength+i], below[i])
+		}
+	}
+
+	if windows, _ := f.Dense(image.NewGray(image.Rect(0, 0, 32, 32)), 8); len(windows) != 0 {
+		t.Fatalf("Test failed. Expected: 0; Actual: %v", len(windows))
+	}
+}
+
+func TestDenseSmallerThanACell(t *testing.T) {
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []image.Point{{2, 300}, {300, 1}} {
+		windows, err := f.Dense(image.NewGray(image.Rect(0, 0, size.X, size.Y)), 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(windows) != 0 {
+			t.Fatalf("Test failed on %v. Expected: 0; Actual: %v", size, len(windows))
+		}
+	}
+}
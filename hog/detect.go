@@ -0,0 +1,120 @@
+package hog
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// Scorer scores the descriptor of one window. Higher scores mean the window
+// is more likely to contain the object.
+type Scorer interface {
+	Score(features []float32) float32
+}
+
+// LinearModel scores a descriptor as its dot product with Weights plus Bias.
+type LinearModel struct {
+	Weights []float32
+	Bias    float32
+}
+
+func (m LinearModel) Score(features []float32) float32 {
+	score := m.Bias
+
+	for i, w := range m.Weights {
+		score += w * features[i]
+	}
+
+	return score
+}
+
+// Dimension returns the descriptor length the model expects.
+func (m LinearModel) Dimension() int {
+	return len(m.Weights)
+}
+
+// Detection is a window that scored above the detection threshold. Box is in
+// the coordinates of the original image and Scale is the factor the image was
+// shrunk by at the pyramid level where the window was found.
+type Detection struct {
+	Box   image.Rectangle
+	Score float32
+	Scale float64
+}
+
+type DetectOptions struct {
+	// ScaleFactor is the ratio between the sizes of two consecutive pyramid
+	// levels. It defaults to 1.2.
+	ScaleFactor float64
+	// Stride is the window stride in pixels at every level. It defaults to
+	// the distance between two blocks.
+	Stride int
+	// Threshold is the lowest score reported as a detection.
+	Threshold float32
+}
+
+// Detect scans an image pyramid of img, shrinking it by opts.ScaleFactor per
+// level until it no longer holds a window, scores every window of every level
+// with scorer and returns the windows that reach opts.Threshold.
+func (f *HOG) Detect(img image.Image, scorer Scorer, opts DetectOptions) ([]Detection, error) {
+	if opts.ScaleFactor == 0 {
+		opts.ScaleFactor = 1.2
+	}
+
+	if opts.ScaleFactor <= 1 {
+		return nil, fmt.Errorf("scale factor must be greater than 1, got %v", opts.ScaleFactor)
+	}
+
+	if opts.Stride == 0 {
+		opts.Stride = f.cellSize * f.blockStride
+	}
+
+	if sized, ok := scorer.(interface{ Dimension() int }); ok && sized.Dimension() != f.FeatureLength() {
+		return nil, fmt.Errorf("model expects %d features but the extractor produces %d", sized.Dimension(), f.FeatureLength())
+	}
+
+	bounds := img.Bounds()
+
+	detections := []Detection{}
+
+	for scale := 1.0; ; scale *= opts.ScaleFactor {
+		width := int(math.Round(float64(bounds.Dx()) / scale))
+		height := int(math.Round(float64(bounds.Dy()) / scale))
+
+		if width < f.width || height < f.height {
+			break
+		}
+
+		level := f.resize(img, width, height)
+
+		windows, err := f.Dense(level, opts.Stride)
+		if err != nil {
+			return nil, err
+		}
+
+		// The rounded level size sets the exact ratio back to the original
+		scaleX := float64(bounds.Dx()) / float64(width)
+		scaleY := float64(bounds.Dy()) / float64(height)
+
+		for _, window := range windows {
+			score := scorer.Score(window.Features)
+
+			if score < opts.Threshold {
+				continue
+			}
+
+			detections = append(detections, Detection{
+				Box: image.Rect(
+					bounds.Min.X+int(math.Round(float64(window.X)*scaleX)),
+					bounds.Min.Y+int(math.Round(float64(window.Y)*scaleY)),
+					bounds.Min.X+int(math.Round(float64(window.X+f.width)*scaleX)),
+					bounds.Min.Y+int(math.Round(float64(window.Y+f.height)*scaleY)),
+				),
+				Score: score,
+				Scale: scale,
+			})
+		}
+	}
+
+	return detections, nil
+}
@@ -0,0 +1,94 @@
+package hog_test
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/kachaje/hog/hog"
+)
+
+func TestDetect(t *testing.T) {
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := loadRGBA(t, "flower.jpg", 64, 128)
+
+	windows, err := f.Dense(template, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model := hog.LinearModel{Weights: windows[0].Features, Bias: -1}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, 256, 256))
+	draw.Draw(canvas, canvas.Rect, image.NewUniform(color.RGBA{128, 128, 128, 255}), image.Point{}, draw.Src)
+	draw.Draw(canvas, image.Rect(64, 32, 128, 160), template, image.Point{}, draw.Src)
+
+	// The same scene at twice the size is only found on the second level
+	large := f.Resize(canvas, 512, 512)
+
+	targets := []struct {
+		img   image.Image
+		box   image.Rectangle
+		scale float64
+	}{
+		{canvas, image.Rect(64, 32, 128, 160), 1},
+		{large, image.Rect(128, 64, 256, 320), 2},
+	}
+
+	for _, row := range targets {
+		detections, err := f.Detect(row.img, model, hog.DetectOptions{ScaleFactor: 2, Stride: 8})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(detections) == 0 {
+			t.Fatal("Test failed. Expected detections")
+		}
+
+		best := detections[0]
+		for _, detection := range detections {
+			if detection.Score > best.Score {
+				best = detection
+			}
+		}
+
+		if best.Box != row.box || best.Scale != row.scale {
+			t.Fatalf("Test failed. Expected: %v at %v; Actual: %v at %v", row.box, row.scale, best.Box, best.Scale)
+		}
+	}
+}
+
+func TestDetectValidation(t *testing.T) {
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := image.NewGray(image.Rect(0, 0, 128, 128))
+
+	if _, err := f.Detect(img, hog.LinearModel{Weights: make([]float32, 10)}, hog.DetectOptions{}); err == nil {
+		t.Fatal("Test failed. Expected an error for a model of the wrong length")
+	}
+
+	model := hog.LinearModel{Weights: make([]float32, f.FeatureLength())}
+
+	if _, err := f.Detect(img, model, hog.DetectOptions{ScaleFactor: 0.5}); err == nil {
+		t.Fatal("Test failed. Expected an error for a scale factor below 1")
+	}
+
+	detections, err := f.Detect(img, model, hog.DetectOptions{Threshold: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 9 windows across the full-size level; the 107x107 level is shorter than
+	// the window
+	if len(detections) != 9 {
+		t.Fatalf("Test failed. Expected: 9; Actual: %v", len(detections))
+	}
+}
@@ -1,13 +1,10 @@
 package hog
 
 import (
-	"encoding/json"
 	"fmt"
 	"image"
 	"image/color"
-	"image/jpeg"
 	"math"
-	"os"
 
 	"golang.org/x/image/draw"
 )
@@ -16,83 +13,127 @@ type HOG struct {
 	numberOfBins int
 	stepSize     int
 	epsilon      float64
+	width        int
+	height       int
+	cellSize     int
+	blockSize    int
+	blockStride  int
+	blockNorm    BlockNorm
+	signed       bool
+	spatial      bool
+	gaussian     bool
+	compression  Compression
+	gamma        float64
+	colour       bool
+	resampler    Resampler
+	fitMode      FitMode
+	pad          color.RGBA
+	semantics    Semantics
 }
 
-func NewHOG(numberOfBins *int, epsilon *float64) *HOG {
+func NewHOG(opts ...Option) (*HOG, error) {
+	config := DefaultConfig()
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return FromConfig(config)
+}
+
+// FromConfig builds an extractor from a complete set of settings, for
+// example one saved alongside a trained model.
+func FromConfig(config Config) (*HOG, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
 	instance := &HOG{
-		numberOfBins: 9,
-		stepSize:     20,
-		epsilon:      1e-05,
+		numberOfBins: config.Bins,
+		epsilon:      config.Epsilon,
+		width:        config.Width,
+		height:       config.Height,
+		cellSize:     config.CellSize,
+		blockSize:    config.BlockSize,
+		blockStride:  config.BlockStride,
+		blockNorm:    config.BlockNorm,
+		signed:       config.Signed,
+		spatial:      config.SpatialInterpolation,
+		gaussian:     config.GaussianWeighting,
+		compression:  config.Compression,
+		gamma:        config.Gamma,
+		colour:       config.Colour,
+		resampler:    config.Resampler,
+		fitMode:      config.Fit,
+		pad:          config.Pad,
+		semantics:    config.Semantics,
 	}
 
-	if numberOfBins != nil {
-		instance.numberOfBins = *numberOfBins
-		instance.stepSize = 180 / instance.numberOfBins
+	instance.stepSize = instance.orientationRange() / instance.numberOfBins
+
+	return instance, nil
+}
+
+// Config returns the settings the extractor was configured with.
+func (h *HOG) Config() Config {
+	return Config{
+		Bins:                 h.numberOfBins,
+		Epsilon:              h.epsilon,
+		Width:                h.width,
+		Height:               h.height,
+		CellSize:             h.cellSize,
+		BlockSize:            h.blockSize,
+		BlockStride:          h.blockStride,
+		BlockNorm:            h.blockNorm,
+		Signed:               h.signed,
+		SpatialInterpolation: h.spatial,
+		GaussianWeighting:    h.gaussian,
+		Compression:          h.compression,
+		Gamma:                h.gamma,
+		Colour:               h.colour,
+		Resampler:            h.resampler,
+		Fit:                  h.fitMode,
+		Pad:                  h.pad,
+		Semantics:            h.semantics,
 	}
+}
 
-	if epsilon != nil {
-		instance.epsilon = *epsilon
+func (h *HOG) orientationRange() int {
+	if h.signed {
+		return 360
 	}
 
-	return instance
+	return 180
 }
 
-func (h *HOG) MagnitudeTheta(img [][]float32) ([][]float32, [][]float32) {
-	height := 128
-	width := 64
-
-	mag := make([][]float32, height)
-	theta := make([][]float32, height)
-
-	for i := range height {
-		var Gx, Gy float32
-
-		mag[i] = make([]float32, 0)
-		theta[i] = make([]float32, 0)
-
-		for j := range width {
-			// Condition for axis 0
-			if j-1 <= 0 || j+1 >= width {
-				if j-1 <= 0 {
-					// Condition if first element
-					Gx = img[i][j+1] - 0
-				} else if j+1 >= len(img[0]) {
-					Gx = 0 - img[i][j-1]
-				}
-				// Condition for first element
-			} else {
-				Gx = img[i][j+1] - img[i][j-1]
-			}
+// FeatureLength returns the length of the descriptor produced for the
+// configured window.
+func (h *HOG) FeatureLength() int {
+	cellsX := h.width / h.cellSize
+	cellsY := h.height / h.cellSize
 
-			// Condition for axis 1
-			if i-1 <= 0 || i+1 >= height {
-				if i-1 <= 0 {
-					Gy = 0 - img[i+1][j]
-				} else if i+1 >= 128 {
-					Gy = img[i-1][j] - 0
-				}
-			} else {
-				Gy = img[i-1][j] - img[i+1][j]
-			}
+	blocksX := (cellsX-h.blockSize)/h.blockStride + 1
+	blocksY := (cellsY-h.blockSize)/h.blockStride + 1
 
-			// Calculating magnitude
-			magnitude := math.Round(math.Sqrt(math.Pow(float64(Gx), 2)+math.Pow(float64(Gy), 2))*1e9) / 1e9
+	return blocksX * blocksY * h.blockSize * h.blockSize * h.numberOfBins
+}
 
-			mag[i] = append(mag[i], float32(magnitude))
+func (h *HOG) MagnitudeTheta(img [][]float32) ([][]float32, [][]float32) {
+	g := h.Gradients(PlaneFromRows(img).SubPlane(0, 0, h.width, h.height))
 
-			var angle float64
+	return g.Magnitude.Rows(), g.Angle.Rows()
+}
 
-			if Gx == 0 {
-				angle = 0.0
-			} else {
-				angle = math.Round(math.Abs(math.Atan(float64(Gy)/float64(Gx))*180/math.Pi)*1e9) / 1e9
-			}
+func (h *HOG) MagnitudeThetaColour(channels [][][]float32) ([][]float32, [][]float32) {
+	planes := make([]Plane, len(channels))
 
-			theta[i] = append(theta[i], float32(angle))
-		}
+	for c, channel := range channels {
+		planes[c] = PlaneFromRows(channel).SubPlane(0, 0, h.width, h.height)
 	}
 
-	return mag, theta
+	g := h.ColourGradients(planes)
+
+	return g.Magnitude.Rows(), g.Angle.Rows()
 }
 
 func (f *HOG) ImgToGray(img image.Image) *image.Gray {
@@ -104,28 +145,108 @@ func (f *HOG) ImgToGray(img image.Image) *image.Gray {
 }
 
 func (f *HOG) Resize(img image.Image, width, height int) image.Image {
+	return f.resize(img, width, height)
+}
+
+func (f *HOG) resize(img image.Image, width, height int) *image.RGBA {
 	newImg := image.NewRGBA(image.Rect(0, 0, width, height))
 
-	draw.NearestNeighbor.Scale(newImg, newImg.Rect, img, img.Bounds(), draw.Over, nil)
+	f.resampler.interpolator().Scale(newImg, newImg.Rect, img, img.Bounds(), draw.Over, nil)
 
 	return newImg
 }
 
-func (f *HOG) ImgToArray(img image.Gray) [][]float32 {
+func (f *HOG) Fit(img image.Image) image.Image {
+	return f.fit(img)
+}
+
+func (f *HOG) fit(img image.Image) *image.RGBA {
+	if f.fitMode == FitStretch {
+		return f.resize(img, f.width, f.height)
+	}
+
 	bounds := img.Bounds()
-	width, height := bounds.Max.X, bounds.Max.Y
+	srcW, srcH := float64(bounds.Dx()), float64(bounds.Dy())
+	dstW, dstH := float64(f.width), float64(f.height)
+
+	newImg := image.NewRGBA(image.Rect(0, 0, f.width, f.height))
+
+	if f.fitMode == FitCrop {
+		scale := math.Max(dstW/srcW, dstH/srcH)
+
+		cropW := int(math.Round(dstW / scale))
+		cropH := int(math.Round(dstH / scale))
+
+		x0 := bounds.Min.X + (bounds.Dx()-cropW)/2
+		y0 := bounds.Min.Y + (bounds.Dy()-cropH)/2
+
+		crop := image.Rect(x0, y0, x0+cropW, y0+cropH)
+
+		f.resampler.interpolator().Scale(newImg, newImg.Rect, img, crop, draw.Over, nil)
 
-	pixelArray := make([][]float32, height)
+		return newImg
+	}
+
+	scale := math.Min(dstW/srcW, dstH/srcH)
+
+	innerW := max(1, int(math.Round(srcW*scale)))
+	innerH := max(1, int(math.Round(srcH*scale)))
+
+	x0 := (f.width - innerW) / 2
+	y0 := (f.height - innerH) / 2
+
+	inner := image.Rect(x0, y0, x0+innerW, y0+innerH)
+
+	if f.fitMode == FitLetterbox {
+		draw.Draw(newImg, newImg.Rect, image.NewUniform(f.pad), image.Point{}, draw.Src)
+	}
 
-	for y := range height {
-		pixelArray[y] = make([]float32, width)
+	f.resampler.interpolator().Scale(newImg, inner, img, bounds, draw.Over, nil)
 
-		for x := range width {
-			pixelArray[y][x] = float32(img.At(x, y).(color.Gray).Y) / 257.0
+	if f.fitMode == FitReplicate {
+		for y := range f.height {
+			for x := range f.width {
+				if (image.Point{x, y}).In(inner) {
+					continue
+				}
+
+				sx := min(max(x, inner.Min.X), inner.Max.X-1)
+				sy := min(max(y, inner.Min.Y), inner.Max.Y-1)
+
+				newImg.SetRGBA(x, y, newImg.RGBAAt(sx, sy))
+			}
 		}
 	}
 
-	return pixelArray
+	return newImg
+}
+
+func (f *HOG) ImgToArray(img image.Gray) [][]float32 {
+	return f.Pixels(&img).Rows()
+}
+
+func (f *HOG) ImgToChannels(img image.Image) [][][]float32 {
+	planes := f.Channels(img)
+
+	channels := make([][][]float32, len(planes))
+
+	for c, plane := range planes {
+		channels[c] = plane.Rows()
+	}
+
+	return channels
+}
+
+func (f *HOG) Compress(pixels [][]float32) [][]float32 {
+	if f.compression == CompressionNone {
+		return pixels
+	}
+
+	p := PlaneFromRows(pixels)
+
+	f.CompressPlane(p)
+
+	return p.Rows()
 }
 
 func (f *HOG) ArrayToImg(data [][]float32, divisor *float32) (image.Image, error) {
@@ -143,19 +264,7 @@ func (f *HOG) ArrayToImg(data [][]float32, divisor *float32) (image.Image, error
 		return nil, fmt.Errorf("inner array of pixel data is empty")
 	}
 
-	img := image.NewGray(image.Rect(0, 0, width, height))
-
-	for y := range height {
-		for x := range width {
-			value := uint8(data[y][x] * factor)
-
-			c := color.Gray{value}
-
-			img.Set(x, y, c)
-		}
-	}
-
-	return img, nil
+	return f.PlaneToImg(PlaneFromRows(data), factor), nil
 }
 
 func (f *HOG) CalculateJ(angle float32) float32 {
@@ -210,13 +319,9 @@ func (f *HOG) BuildBin(magnitudes, angles [][]float32, i, j, step int) []float32
 
 			valueJ, Vj, Vj_1 := f.BuildRow(magnitudeValues[k][l], angleValues[k][l])
 
-			if valueJ < 0 {
-				bin[step] += Vj
-				bin[0] += Vj_1
-			} else {
-				bin[valueJ] += Vj
-				bin[valueJ+1] += Vj_1
-			}
+			// Bins are circular so the first and last bins are neighbours
+			bin[(valueJ+f.numberOfBins)%f.numberOfBins] += Vj
+			bin[(valueJ+1)%f.numberOfBins] += Vj_1
 		}
 	}
 
@@ -224,34 +329,28 @@ func (f *HOG) BuildBin(magnitudes, angles [][]float32, i, j, step int) []float32
 }
 
 func (f *HOG) HistogramPointsNine(magnitudes, angles [][]float32) [][][]float32 {
-	hist := make([][][]float32, 0)
+	g := Gradients{Magnitude: PlaneFromRows(magnitudes), Angle: PlaneFromRows(angles)}
 
-	step := 8
-	height := len(magnitudes)
-	width := len(magnitudes[0])
-
-	for i := 0; i < height; i += step {
-		temp := make([][]float32, 0)
+	return f.Histogram(g).Nested()
+}
 
-		for j := 0; j < width; j += step {
-			bins := f.BuildBin(magnitudes, angles, i, j, step)
+func (f *HOG) HistogramTrilinear(magnitudes, angles [][]float32) [][][]float32 {
+	g := Gradients{Magnitude: PlaneFromRows(magnitudes), Angle: PlaneFromRows(angles)}
 
-			temp = append(temp, bins)
-		}
+	hist := NewGrid(g.Magnitude.Height/f.cellSize, g.Magnitude.Width/f.cellSize, f.numberOfBins)
 
-		hist = append(hist, temp)
-	}
+	f.trilinearInto(g, hist)
 
-	return hist
+	return hist.Nested()
 }
 
 func (f *HOG) FetchHistValues(hist [][][]float32, i, j int) [][][]float32 {
 	values := make([][][]float32, 0)
 
-	for k := range 2 {
+	for k := range f.blockSize {
 		row := [][]float32{}
 
-		for l := range 2 {
+		for l := range f.blockSize {
 			row = append(row, hist[i+k][j+l])
 		}
 
@@ -283,34 +382,28 @@ func (f *HOG) CalculateV2(finalVector []float32, k float32) []float32 {
 	return result
 }
 
-func (f *HOG) CreateFeatures(hist [][][]float32) [][][]float32 {
-	featureVectors := [][][]float32{}
-	epsilon := 1e-05
+func (f *HOG) NormalizeBlock(finalVector []float32) []float32 {
+	result := make([]float32, len(finalVector))
 
-	_ = epsilon
+	copy(result, finalVector)
 
-	for i := range len(hist) - 1 {
-		temp := [][]float32{}
-		for j := range len(hist[0]) - 1 {
-			values := f.FetchHistValues(hist, i, j)
+	f.normalize(result)
 
-			finalVector := []float32{}
-			for _, k := range values {
-				for _, l := range k {
-					finalVector = append(finalVector, l...)
-				}
-			}
+	return result
+}
 
-			k := f.CalculateK(finalVector)
+func (f *HOG) CreateFeatures(hist [][][]float32) [][][]float32 {
+	return f.Blocks(GridFromNested(hist)).Nested()
+}
 
-			finalVector = f.CalculateV2(finalVector, k)
+func (f *HOG) BlockWeights() [][]float32 {
+	return f.blockWeights().Rows()
+}
 
-			temp = append(temp, finalVector)
-		}
-		featureVectors = append(featureVectors, temp)
-	}
+func (f *HOG) CreateWeightedFeatures(magnitudes, angles [][]float32) [][][]float32 {
+	g := Gradients{Magnitude: PlaneFromRows(magnitudes), Angle: PlaneFromRows(angles)}
 
-	return featureVectors
+	return f.WeightedBlocks(g).Nested()
 }
 
 func (h *HOG) FlattenArray(data any) []float32 {
@@ -340,105 +433,124 @@ func (h *HOG) FlattenArray(data any) []float32 {
 	return result
 }
 
-func (f *HOG) HOG(img image.Image, debug bool) (image.Image, []float32, error) {
-	var hogImg image.Image
-	var features []float32
+// HOG fits img to the window and returns its glyph visualization and
+// descriptor. If sink is not nil, every intermediate stage is written to it.
+func (f *HOG) HOG(img image.Image, sink Sink) (image.Image, []float32, error) {
+	return f.run(img, sink, nil)
+}
 
-	resizedImg := f.Resize(img, 64, 128)
+// run is the pipeline behind HOG and Trace. Stages are written to sink and
+// recorded in trace when those are not nil.
+func (f *HOG) run(img image.Image, sink Sink, trace *Trace) (image.Image, []float32, error) {
+	resizedImg := f.fit(img)
 
-	if debug {
-		filename := "outputResized.jpg"
+	if sink != nil || trace != nil {
+		grayImg := f.ImgToGray(resizedImg)
 
-		outputFile, err := os.Create(filename)
-		if err != nil {
-			return nil, nil, err
+		if sink != nil {
+			if err := writeJPEG(sink, "outputResized.jpg", resizedImg); err != nil {
+				return nil, nil, err
+			}
+
+			if err := writeJPEG(sink, "outputGray.jpg", grayImg); err != nil {
+				return nil, nil, err
+			}
 		}
-		defer func() {
-			outputFile.Close()
-		}()
 
-		err = jpeg.Encode(outputFile, resizedImg, nil)
-		if err != nil {
-			return nil, nil, err
+		if trace != nil {
+			trace.Resized = resizedImg
+			trace.Gray = grayImg
 		}
 	}
 
-	grayImg := f.ImgToGray(resizedImg)
+	pixels := f.Pixels(resizedImg)
 
-	if debug {
-		filename := "outputGray.jpg"
-
-		outputFile, err := os.Create(filename)
-		if err != nil {
-			return nil, nil, err
-		}
-		defer func() {
-			outputFile.Close()
-		}()
-
-		err = jpeg.Encode(outputFile, grayImg, nil)
-		if err != nil {
+	if sink != nil {
+		if err := writeJSON(sink, "outputDump.json", pixels.Rows()); err != nil {
 			return nil, nil, err
 		}
 	}
 
-	dump := f.ImgToArray(*grayImg)
+	if trace != nil {
+		trace.Pixels = pixels
 
-	if debug {
-		payload, _ := json.MarshalIndent(dump, "", "  ")
-
-		os.WriteFile("outputDump.json", payload, 0644)
+		// Compression works in place, so the trace keeps its own copy
+		if f.compression != CompressionNone && !f.colour {
+			trace.Pixels = NewPlane(pixels.Width, pixels.Height)
+			copy(trace.Pixels.Data, pixels.Data)
+		}
 	}
 
-	magnitudes, angles := f.MagnitudeTheta(dump)
+	var gradients Gradients
 
-	if debug {
-		payload, _ := json.MarshalIndent(magnitudes, "", "  ")
+	switch {
+	case f.semantics == SemanticsSkimage:
+		// From the image itself, so gray levels are never rounded to float32
+		gradients = f.skimageGradients(f.skimageChannels(resizedImg))
+	case f.colour:
+		channels := f.Channels(resizedImg)
 
-		os.WriteFile("outputMagnitudes.json", payload, 0644)
+		for _, channel := range channels {
+			f.CompressPlane(channel)
+		}
 
-		payload, _ = json.MarshalIndent(angles, "", "  ")
+		gradients = f.ColourGradients(channels)
+	default:
+		f.CompressPlane(pixels)
 
-		os.WriteFile("outputAngles.json", payload, 0644)
+		gradients = f.Gradients(pixels)
 	}
 
-	histogram := f.HistogramPointsNine(magnitudes, angles)
-
-	if debug {
-		payload, _ := json.MarshalIndent(histogram, "", "  ")
+	if sink != nil {
+		if err := writeJSON(sink, "outputMagnitudes.json", gradients.Magnitude.Rows()); err != nil {
+			return nil, nil, err
+		}
 
-		os.WriteFile("outputHist.json", payload, 0644)
+		if err := writeJSON(sink, "outputAngles.json", gradients.Angle.Rows()); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	features = f.FlattenArray(f.CreateFeatures(histogram))
-
-	if debug {
-		payload, _ := json.MarshalIndent(features, "", "  ")
+	histogram := f.Histogram(gradients)
 
-		os.WriteFile("outputFeatures.json", payload, 0644)
+	if sink != nil {
+		if err := writeJSON(sink, "outputHist.json", histogram.Nested()); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	hogImg, err := f.ArrayToImg(magnitudes, nil)
-	if err != nil {
-		return nil, nil, err
+	var blocks Grid
+
+	if f.gaussian {
+		blocks = f.WeightedBlocks(gradients)
+	} else {
+		blocks = f.Blocks(histogram)
 	}
 
-	if debug {
-		filename := "outputHOG.jpg"
+	features := blocks.Flatten()
 
-		outputFile, err := os.Create(filename)
-		if err != nil {
+	if sink != nil {
+		if err := writeJSON(sink, "outputFeatures.json", features); err != nil {
 			return nil, nil, err
 		}
-		defer func() {
-			outputFile.Close()
-		}()
+	}
 
-		err = jpeg.Encode(outputFile, hogImg, nil)
-		if err != nil {
+	hogImg := f.Render(histogram, RenderOptions{})
+
+	if sink != nil {
+		if err := writeJPEG(sink, "outputHOG.jpg", hogImg); err != nil {
 			return nil, nil, err
 		}
 	}
 
+	if trace != nil {
+		trace.Magnitude = gradients.Magnitude
+		trace.Angle = gradients.Angle
+		trace.Histogram = histogram
+		trace.Blocks = blocks
+		trace.Features = features
+		trace.Image = hogImg
+	}
+
 	return hogImg, features, nil
 }
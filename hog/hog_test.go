@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"image"
+	"image/color"
 	"math"
 	"os"
 	"path/filepath"
@@ -27,7 +28,10 @@ func TestImgToArray(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	grayImg := f.ImgToGray(img)
 
@@ -76,7 +80,10 @@ func TestImgToGray(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	grayImg := f.ImgToGray(img)
 
@@ -110,7 +117,10 @@ func TestResize(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	newImg := f.Resize(img, 64, 128)
 
@@ -157,7 +167,10 @@ func TestArrayToImg(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	magFilename := "outputMag.png"
 	thetaFilename := "outputTheta.png"
@@ -219,7 +232,10 @@ func TestPartition(t *testing.T) {
 
 	step := 3
 
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	result := f.Partition(data, 1, 2, step)
 
@@ -299,7 +315,10 @@ Actual: %#v`, target, result)
 }
 
 func TestCalculateJ(t *testing.T) {
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	targets := map[float32]float32{
 		89.699551773: 3,
@@ -322,7 +341,10 @@ func TestCalculateJ(t *testing.T) {
 }
 
 func TestCalculateCJ(t *testing.T) {
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	targets := map[float32]float32{
 		4: 90.0,
@@ -342,7 +364,10 @@ func TestCalculateCJ(t *testing.T) {
 }
 
 func TestCalculateValueJ(t *testing.T) {
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	targets := map[float32]map[string]float32{
 		0.002121697: {
@@ -391,7 +416,10 @@ func TestCalculateValueJ(t *testing.T) {
 }
 
 func TestBuildRow(t *testing.T) {
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	files, err := os.ReadDir("./fixtures/data/points")
 	if err != nil {
@@ -461,7 +489,10 @@ func TestBuildBin(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	result := f.BuildBin(magData, thetaData, 0, 0, 8)
 
@@ -509,7 +540,10 @@ func TestHistogramPointsNine(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	hist := f.HistogramPointsNine(magData, thetaData)
 
@@ -549,7 +583,10 @@ func TestHistogramPointsNine(t *testing.T) {
 }
 
 func TestFetchHistValues(t *testing.T) {
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	var hist [][][]float32
 
@@ -596,7 +633,10 @@ func TestFetchHistValues(t *testing.T) {
 }
 
 func TestCalculateK(t *testing.T) {
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	var hist [][][]float32
 
@@ -649,7 +689,10 @@ func TestCalculateK(t *testing.T) {
 }
 
 func TestCalculateV2(t *testing.T) {
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	var hist [][][]float32
 
@@ -714,7 +757,10 @@ func TestCalculateV2(t *testing.T) {
 }
 
 func TestCreateFeatures(t *testing.T) {
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	var target, features [][][]float32
 
@@ -801,7 +847,10 @@ func TestMagnitudeTheta(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	mag, theta := f.MagnitudeTheta(targetData)
 
@@ -823,9 +872,19 @@ Actual: %#v`, magData, mag)
 		}
 	}
 
+	// The reference folds angles into 0-90 degrees and reports 0 where Gx is 0
 	for i := range thetaData {
 		for j := range thetaData[i] {
-			if theta[i][j] != thetaData[i][j] {
+			folded := theta[i][j]
+			if folded > 90 {
+				folded = 180 - folded
+			}
+
+			if thetaData[i][j] == 0 && folded == 90 {
+				continue
+			}
+
+			if math.Abs(float64(folded-thetaData[i][j])) > 1e-4 {
 				t.Fatalf(`Test failed. 
 Expected: %#v; 
 Actual: %#v`, thetaData, theta)
@@ -835,7 +894,10 @@ Actual: %#v`, thetaData, theta)
 }
 
 func TestHOG(t *testing.T) {
-	f := hog.NewHOG(nil, nil)
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	reader, err := os.Open(filepath.Join("..", "data", "flower.jpg"))
 	if err != nil {
@@ -848,7 +910,7 @@ func TestHOG(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	hogImg, features, err := f.HOG(img, false)
+	hogImg, features, err := f.HOG(img, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -867,7 +929,10 @@ func TestHOG(t *testing.T) {
 }
 
 func TestFlattenArray(t *testing.T) {
-	h := hog.NewHOG(nil, nil)
+	h, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	data := [][][][]float32{
 		{
@@ -893,3 +958,712 @@ func TestFlattenArray(t *testing.T) {
 		}
 	}
 }
+
+func TestHOGWindow(t *testing.T) {
+	f, err := hog.NewHOG(hog.WithWindow(64, 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := os.Open(filepath.Join("..", "data", "face.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	img, _, err := image.Decode(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hogImg, features, err := f.HOG(img, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hogImg.Bounds().Dx() != 64 || hogImg.Bounds().Dy() != 64 {
+		t.Fatalf("Test failed. Expected: 64x64; Actual: %v", hogImg.Bounds())
+	}
+
+	target := 1764
+
+	if f.FeatureLength() != target {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", target, f.FeatureLength())
+	}
+
+	if len(features) != target {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", target, len(features))
+	}
+}
+
+func TestHOGGeometry(t *testing.T) {
+	reader, err := os.Open(filepath.Join("..", "data", "flower.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	img, _, err := image.Decode(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	targets := []struct {
+		cellSize, blockSize, blockStride, length int
+	}{
+		{8, 2, 1, 3780},
+		{8, 2, 2, 1152},
+		{8, 3, 1, 6804},
+		{16, 2, 1, 756},
+		{6, 2, 1, 6480},
+	}
+
+	for _, row := range targets {
+		f, err := hog.NewHOG(hog.WithCellSize(row.cellSize), hog.WithBlock(row.blockSize, row.blockStride))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, features, err := f.HOG(img, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if f.FeatureLength() != row.length {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", row.length, f.FeatureLength())
+		}
+
+		if len(features) != row.length {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", row.length, len(features))
+		}
+	}
+}
+
+func TestNormalizeBlock(t *testing.T) {
+	vector := []float32{0, 0.1, 0.2, 3, 0.4, 0.5, 0, 0.7, 0.8}
+
+	targets := map[hog.BlockNorm][]float32{
+		hog.NormL2:     {0, 0.030729, 0.061458, 0.921875, 0.122917, 0.153646, 0, 0.215104, 0.245833},
+		hog.NormL2Hys:  {0, 0.076011, 0.152022, 0.494714, 0.304044, 0.380055, 0, 0.494714, 0.494714},
+		hog.NormL1:     {0, 0.017544, 0.035088, 0.526315, 0.070175, 0.087719, 0, 0.122807, 0.140351},
+		hog.NormL1Sqrt: {0, 0.132453, 0.187317, 0.725476, 0.264906, 0.296174, 0, 0.350438, 0.374634},
+	}
+
+	for norm, target := range targets {
+		f, err := hog.NewHOG(hog.WithBlockNorm(norm))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result := f.NormalizeBlock(vector)
+
+		for i := range target {
+			if math.Abs(float64(result[i]-target[i])) > 1e-5 {
+				t.Fatalf(`Test failed on %v. 
+Expected: %v; 
+Actual: %v`, norm, target, result)
+			}
+		}
+	}
+}
+
+func TestMagnitudeThetaSigned(t *testing.T) {
+	ramp := func(fn func(i, j int) float32) [][]float32 {
+		data := make([][]float32, 8)
+		for i := range data {
+			data[i] = make([]float32, 8)
+			for j := range data[i] {
+				data[i][j] = fn(i, j)
+			}
+		}
+		return data
+	}
+
+	targets := []struct {
+		data             [][]float32
+		unsigned, signed float32
+	}{
+		{ramp(func(i, j int) float32 { return float32(j) }), 0, 0},
+		{ramp(func(i, j int) float32 { return -float32(j) }), 0, 180},
+		{ramp(func(i, j int) float32 { return float32(i) }), 90, 270},
+		{ramp(func(i, j int) float32 { return -float32(i) }), 90, 90},
+		{ramp(func(i, j int) float32 { return float32(j - i) }), 45, 45},
+		{ramp(func(i, j int) float32 { return float32(j + i) }), 135, 315},
+	}
+
+	for _, row := range targets {
+		unsigned, err := hog.NewHOG(hog.WithWindow(8, 8), hog.WithBlock(1, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed, err := hog.NewHOG(hog.WithWindow(8, 8), hog.WithBlock(1, 1), hog.WithSigned(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, theta := unsigned.MagnitudeTheta(row.data)
+		if theta[4][4] != row.unsigned {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", row.unsigned, theta[4][4])
+		}
+
+		_, theta = signed.MagnitudeTheta(row.data)
+		if theta[4][4] != row.signed {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", row.signed, theta[4][4])
+		}
+	}
+}
+
+func TestBuildBinWrap(t *testing.T) {
+	targets := []struct {
+		signed bool
+		angle  float32
+		last   float32
+		first  float32
+	}{
+		{false, 175, 0.75, 0.25},
+		{false, 5, 0.25, 0.75},
+		{true, 350, 0.75, 0.25},
+		{true, 10, 0.25, 0.75},
+	}
+
+	for _, row := range targets {
+		f, err := hog.NewHOG(hog.WithSigned(row.signed))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bin := f.BuildBin([][]float32{{1}}, [][]float32{{row.angle}}, 0, 0, 1)
+
+		if bin[8] != row.last || bin[0] != row.first {
+			t.Fatalf("Test failed. Expected: %v, %v; Actual: %v", row.last, row.first, bin)
+		}
+	}
+}
+
+func TestHistogramTrilinear(t *testing.T) {
+	magnitudes := make([][]float32, 16)
+	angles := make([][]float32, 16)
+	for i := range magnitudes {
+		magnitudes[i] = make([]float32, 16)
+		angles[i] = make([]float32, 16)
+	}
+
+	magnitudes[7][7] = 1
+	angles[7][7] = 10
+
+	f, err := hog.NewHOG(hog.WithSpatialInterpolation(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hist := f.HistogramPointsNine(magnitudes, angles)
+
+	targets := [][]float32{
+		{0.31640625, 0.24609375},
+		{0.24609375, 0.19140625},
+	}
+
+	for i := range targets {
+		for j := range targets[i] {
+			if math.Abs(float64(hist[i][j][0]-targets[i][j])) > 1e-6 {
+				t.Fatalf("Test failed. Expected: %v; Actual: %v", targets[i][j], hist[i][j][0])
+			}
+
+			for k := 1; k < 9; k++ {
+				if hist[i][j][k] != 0 {
+					t.Fatalf("Test failed. Expected: 0; Actual: %v", hist[i][j][k])
+				}
+			}
+		}
+	}
+
+	// Votes near the image border have no neighbour to share with
+	magnitudes[7][7] = 0
+	magnitudes[0][0] = 1
+	angles[0][0] = 10
+
+	hist = f.HistogramPointsNine(magnitudes, angles)
+
+	if hist[0][0][0] != 0.31640625 {
+		t.Fatalf("Test failed. Expected: 0.31640625; Actual: %v", hist[0][0][0])
+	}
+}
+
+func TestHistogramSpatialScale(t *testing.T) {
+	magnitudes := make([][]float32, 128)
+	angles := make([][]float32, 128)
+	for i := range magnitudes {
+		magnitudes[i] = make([]float32, 64)
+		angles[i] = make([]float32, 64)
+
+		for j := range magnitudes[i] {
+			magnitudes[i][j] = 1
+			angles[i][j] = 10
+		}
+	}
+
+	plain, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spatial, err := hog.NewHOG(hog.WithSpatialInterpolation(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One vote per cell without interpolation, every pixel's with it
+	for f, target := range map[*hog.HOG]float32{plain: 1, spatial: 64} {
+		var total float32
+		for _, value := range f.HistogramPointsNine(magnitudes, angles)[5][3] {
+			total += value
+		}
+
+		if math.Abs(float64(total-target)) > 1e-4 {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", target, total)
+		}
+	}
+}
+
+func TestBlockWeights(t *testing.T) {
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weights := f.BlockWeights()
+
+	if len(weights) != 16 || len(weights[0]) != 16 {
+		t.Fatalf("Test failed. Expected: 16x16; Actual: %vx%v", len(weights), len(weights[0]))
+	}
+
+	targets := map[[2]int]float32{
+		{0, 0}:   float32(math.Exp(-2 * 7.5 * 7.5 / 128)),
+		{7, 8}:   float32(math.Exp(-2 * 0.5 * 0.5 / 128)),
+		{15, 15}: float32(math.Exp(-2 * 7.5 * 7.5 / 128)),
+		{0, 15}:  float32(math.Exp(-2 * 7.5 * 7.5 / 128)),
+	}
+
+	for point, target := range targets {
+		result := weights[point[0]][point[1]]
+
+		if math.Abs(float64(result-target)) > 1e-6 {
+			t.Fatalf("Test failed on %v. Expected: %v; Actual: %v", point, target, result)
+		}
+	}
+}
+
+func TestCreateWeightedFeatures(t *testing.T) {
+	var magData, thetaData [][]float32
+
+	data, err := os.ReadFile("./fixtures/magnitudes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = json.Unmarshal(data, &magData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err = os.ReadFile("./fixtures/thetas.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = json.Unmarshal(data, &thetaData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := hog.NewHOG(hog.WithSpatialInterpolation(true), hog.WithGaussianWeighting(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weighted := f.FlattenArray(f.CreateWeightedFeatures(magData, thetaData))
+	plain := f.FlattenArray(f.CreateFeatures(f.HistogramPointsNine(magData, thetaData)))
+
+	if len(weighted) != f.FeatureLength() {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", f.FeatureLength(), len(weighted))
+	}
+
+	same := true
+	for i := range weighted {
+		if math.IsNaN(float64(weighted[i])) {
+			t.Fatalf("Test failed. Feature %v is NaN", i)
+		}
+
+		if weighted[i] != plain[i] {
+			same = false
+		}
+	}
+
+	if same {
+		t.Fatal("Test failed. Expected weighted features to differ")
+	}
+}
+
+func TestCreateWeightedFeaturesEveryPixel(t *testing.T) {
+	var magData, thetaData [][]float32
+
+	data, err := os.ReadFile("./fixtures/magnitudes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = json.Unmarshal(data, &magData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err = os.ReadFile("./fixtures/thetas.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = json.Unmarshal(data, &thetaData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := hog.NewHOG(hog.WithGaussianWeighting(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := f.FlattenArray(f.CreateWeightedFeatures(magData, thetaData))
+
+	// An interior pixel of the first cell, away from the one pixel that votes
+	// in the unweighted histograms
+	magData[3][3] += 1
+	thetaData[3][3] = 45
+
+	after := f.FlattenArray(f.CreateWeightedFeatures(magData, thetaData))
+
+	same := true
+	for i := range before {
+		if before[i] != after[i] {
+			same = false
+		}
+	}
+
+	if same {
+		t.Fatal("Test failed. Expected an interior pixel to change the weighted features")
+	}
+}
+
+func TestCompress(t *testing.T) {
+	pixels := [][]float32{
+		{0, 0.25, 0.5},
+		{0.75, 1, 0.04},
+	}
+
+	targets := []struct {
+		compression hog.Compression
+		gamma       float64
+		fn          func(float64) float64
+	}{
+		{hog.CompressionNone, 0, func(v float64) float64 { return v }},
+		{hog.CompressionGamma, 0.5, math.Sqrt},
+		{hog.CompressionGamma, 2.2, func(v float64) float64 { return math.Pow(v, 2.2) }},
+		{hog.CompressionSqrt, 0, math.Sqrt},
+		{hog.CompressionLog, 0, math.Log1p},
+	}
+
+	for _, row := range targets {
+		f, err := hog.NewHOG(hog.WithCompression(row.compression, row.gamma))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result := f.Compress(pixels)
+
+		for i := range pixels {
+			for j := range pixels[i] {
+				target := float32(row.fn(float64(pixels[i][j])))
+
+				if math.Abs(float64(result[i][j]-target)) > 1e-6 {
+					t.Fatalf("Test failed on %v. Expected: %v; Actual: %v", row.compression, target, result[i][j])
+				}
+			}
+		}
+	}
+
+	if pixels[0][1] != 0.25 {
+		t.Fatal("Test failed. Input was modified")
+	}
+}
+
+func TestHOGColour(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 128))
+
+	// Red and this shade of green share the same luma
+	for y := range 128 {
+		for x := range 64 {
+			if x < 32 {
+				img.Set(x, y, color.RGBA{255, 0, 0, 255})
+			} else {
+				img.Set(x, y, color.RGBA{0, 129, 0, 255})
+			}
+		}
+	}
+
+	gray, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+	colour, err := hog.NewHOG(hog.WithColour(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grayImg, grayFeatures, err := gray.HOG(img, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	colourImg, colourFeatures, err := colour.HOG(img, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(grayFeatures) != len(colourFeatures) {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", len(grayFeatures), len(colourFeatures))
+	}
+
+	// Only colour gradients see the boundary, drawn as a vertical glyph
+	// through the centre of the cells left of it
+	if value := grayImg.At(28, 68).(color.Gray).Y; value != 0 {
+		t.Fatalf("Test failed. Expected: 0; Actual: %v", value)
+	}
+
+	if value := colourImg.At(28, 68).(color.Gray).Y; value == 0 {
+		t.Fatalf("Test failed. Expected a glyph; Actual: %v", value)
+	}
+}
+
+func TestMagnitudeThetaColour(t *testing.T) {
+	channels := make([][][]float32, 3)
+	for c := range channels {
+		channels[c] = make([][]float32, 8)
+		for i := range 8 {
+			channels[c][i] = make([]float32, 8)
+			for j := range 8 {
+				switch c {
+				case 0:
+					channels[c][i][j] = float32(j)
+				case 1:
+					channels[c][i][j] = 2 * float32(i)
+				}
+			}
+		}
+	}
+
+	f, err := hog.NewHOG(hog.WithWindow(8, 8), hog.WithBlock(1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mag, theta := f.MagnitudeThetaColour(channels)
+
+	if mag[4][4] != 4 || theta[4][4] != 90 {
+		t.Fatalf("Test failed. Expected: 4, 90; Actual: %v, %v", mag[4][4], theta[4][4])
+	}
+
+	channels[0][4][5] = 20
+
+	mag, theta = f.MagnitudeThetaColour(channels)
+
+	if mag[4][4] != 17 || theta[4][4] != 0 {
+		t.Fatalf("Test failed. Expected: 17, 0; Actual: %v, %v", mag[4][4], theta[4][4])
+	}
+}
+
+func TestResampler(t *testing.T) {
+	reader, err := os.Open(filepath.Join("..", "data", "flower.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	img, _, err := image.Decode(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	targets := map[hog.Resampler]string{
+		hog.ResampleNearest:        "nearest",
+		hog.ResampleApproxBiLinear: "approx-bilinear",
+		hog.ResampleBiLinear:       "bilinear",
+		hog.ResampleCatmullRom:     "catmull-rom",
+	}
+
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, reference, err := f.HOG(img, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for resampler, name := range targets {
+		f, err := hog.NewHOG(hog.WithResampler(resampler))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if f.Config().Resampler != resampler || resampler.String() != name {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", name, f.Config().Resampler)
+		}
+
+		_, features, err := f.HOG(img, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(features) != len(reference) {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", len(reference), len(features))
+		}
+
+		same := true
+		for i := range features {
+			if features[i] != reference[i] {
+				same = false
+				break
+			}
+		}
+
+		if same != (resampler == hog.ResampleNearest) {
+			t.Fatalf("Test failed. Unexpected features for %v", resampler)
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 100))
+
+	for y := range 100 {
+		for x := range 100 {
+			if x < 50 {
+				img.Set(x, y, color.RGBA{0, 0, 0, 255})
+			} else {
+				img.Set(x, y, color.RGBA{255, 255, 255, 255})
+			}
+		}
+	}
+
+	pad := color.RGBA{0, 0, 255, 255}
+
+	targets := []struct {
+		mode   hog.FitMode
+		points map[image.Point]color.RGBA
+	}{
+		{hog.FitStretch, map[image.Point]color.RGBA{
+			{0, 0}:    {0, 0, 0, 255},
+			{63, 0}:   {255, 255, 255, 255},
+			{31, 127}: {0, 0, 0, 255},
+		}},
+		{hog.FitCrop, map[image.Point]color.RGBA{
+			{0, 0}:    {0, 0, 0, 255},
+			{31, 64}:  {0, 0, 0, 255},
+			{32, 64}:  {255, 255, 255, 255},
+			{63, 127}: {255, 255, 255, 255},
+		}},
+		{hog.FitLetterbox, map[image.Point]color.RGBA{
+			{0, 0}:    pad,
+			{63, 31}:  pad,
+			{0, 32}:   {0, 0, 0, 255},
+			{63, 95}:  {255, 255, 255, 255},
+			{63, 127}: pad,
+		}},
+		{hog.FitReplicate, map[image.Point]color.RGBA{
+			{0, 0}:    {0, 0, 0, 255},
+			{63, 0}:   {255, 255, 255, 255},
+			{0, 127}:  {0, 0, 0, 255},
+			{63, 127}: {255, 255, 255, 255},
+		}},
+	}
+
+	for _, row := range targets {
+		f, err := hog.NewHOG(hog.WithFit(row.mode, pad))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result := f.Fit(img)
+
+		if result.Bounds() != image.Rect(0, 0, 64, 128) {
+			t.Fatalf("Test failed. Expected: 64x128; Actual: %v", result.Bounds())
+		}
+
+		for point, target := range row.points {
+			value := color.RGBAModel.Convert(result.At(point.X, point.Y)).(color.RGBA)
+
+			if value != target {
+				t.Fatalf("Test failed on %v at %v. Expected: %v; Actual: %v", row.mode, point, target, value)
+			}
+		}
+	}
+}
+
+func TestHOGAllocations(t *testing.T) {
+	reader, err := os.Open(filepath.Join("..", "data", "flower.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	img, _, err := image.Decode(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		if _, _, err := f.HOG(img, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if allocs > 16 {
+		t.Fatalf("Test failed. Expected at most 16 allocations; Actual: %v", allocs)
+	}
+}
+
+func TestPixels(t *testing.T) {
+	reader, err := os.Open(filepath.Join("..", "data", "flower.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	img, _, err := image.Decode(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resized := f.Resize(img, 64, 128)
+
+	result := f.Pixels(resized)
+	target := f.ImgToArray(*f.ImgToGray(resized))
+
+	for y := range target {
+		for x := range target[y] {
+			if result.At(x, y) != target[y][x] {
+				t.Fatalf("Test failed at %v, %v. Expected: %v; Actual: %v", x, y, target[y][x], result.At(x, y))
+			}
+		}
+	}
+}
@@ -0,0 +1,514 @@
+package hog
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Pixels converts img to a plane of gray intensities, scaled the same way as
+// ImgToArray.
+func (f *HOG) Pixels(img image.Image) Plane {
+	if f.semantics == SemanticsSkimage {
+		return f.skimagePixels(img)
+	}
+
+	bounds := img.Bounds()
+	p := NewPlane(bounds.Dx(), bounds.Dy())
+
+	switch src := img.(type) {
+	case *image.Gray:
+		for y := range p.Height {
+			offset := src.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+			row := p.Row(y)
+
+			for x := range row {
+				row[x] = float32(src.Pix[offset+x]) / 257.0
+			}
+		}
+	case *image.RGBA:
+		for y := range p.Height {
+			offset := src.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+			row := p.Row(y)
+
+			for x := range row {
+				pix := src.Pix[offset+4*x:]
+
+				// Same weights and rounding as color.GrayModel
+				r := uint32(pix[0]) * 0x101
+				g := uint32(pix[1]) * 0x101
+				b := uint32(pix[2]) * 0x101
+
+				gray := (19595*r + 38470*g + 7471*b + 1<<15) >> 24
+
+				row[x] = float32(uint8(gray)) / 257.0
+			}
+		}
+	default:
+		for y := range p.Height {
+			row := p.Row(y)
+
+			for x := range row {
+				gray := color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray)
+
+				row[x] = float32(gray.Y) / 257.0
+			}
+		}
+	}
+
+	return p
+}
+
+// Channels splits img into red, green and blue planes backed by a single
+// allocation.
+func (f *HOG) Channels(img image.Image) []Plane {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	data := make([]float32, 3*width*height)
+
+	channels := make([]Plane, 3)
+	for c := range channels {
+		channels[c] = Plane{
+			Width:  width,
+			Height: height,
+			Stride: width,
+			Data:   data[c*width*height : (c+1)*width*height],
+		}
+	}
+
+	src, isRGBA := img.(*image.RGBA)
+
+	scale := float32(257)
+	if f.semantics == SemanticsSkimage {
+		scale = 255
+	}
+
+	for y := range height {
+		for x := range width {
+			var r, g, b uint32
+
+			if isRGBA {
+				pix := src.Pix[src.PixOffset(bounds.Min.X+x, bounds.Min.Y+y):]
+
+				r, g, b = uint32(pix[0]), uint32(pix[1]), uint32(pix[2])
+			} else {
+				r, g, b, _ = img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+
+				r, g, b = r>>8, g>>8, b>>8
+			}
+
+			channels[0].Set(x, y, float32(r)/scale)
+			channels[1].Set(x, y, float32(g)/scale)
+			channels[2].Set(x, y, float32(b)/scale)
+		}
+	}
+
+	return channels
+}
+
+// CompressPlane applies the configured intensity compression to p in place.
+func (f *HOG) CompressPlane(p Plane) {
+	if f.compression == CompressionNone {
+		return
+	}
+
+	for y := range p.Height {
+		row := p.Row(y)
+
+		for x, value := range row {
+			row[x] = float32(f.compress(float64(value)))
+		}
+	}
+}
+
+// compress applies the configured intensity compression to one value.
+func (f *HOG) compress(v float64) float64 {
+	switch f.compression {
+	case CompressionGamma:
+		return math.Pow(v, f.gamma)
+	case CompressionSqrt:
+		return math.Sqrt(v)
+	case CompressionLog:
+		return math.Log1p(v)
+	}
+
+	return v
+}
+
+// Gradients computes the gradient magnitude and orientation of every pixel
+// of p.
+func (f *HOG) Gradients(p Plane) Gradients {
+	if f.semantics == SemanticsSkimage {
+		return f.skimageGradients([]plane64{newPlane64(p)})
+	}
+
+	g := NewGradients(p.Width, p.Height)
+
+	for y := range p.Height {
+		for x := range p.Width {
+			magnitude, angle := f.gradientAt(p, x, y)
+
+			g.Magnitude.Set(x, y, magnitude)
+			g.Angle.Set(x, y, angle)
+		}
+	}
+
+	return g
+}
+
+// ColourGradients computes the gradients of every channel and keeps, for each
+// pixel, the channel with the largest magnitude.
+func (f *HOG) ColourGradients(channels []Plane) Gradients {
+	if f.semantics == SemanticsSkimage {
+		exact := make([]plane64, len(channels))
+		for c, channel := range channels {
+			exact[c] = newPlane64(channel)
+		}
+
+		return f.skimageGradients(exact)
+	}
+
+	width, height := channels[0].Width, channels[0].Height
+
+	g := NewGradients(width, height)
+
+	for y := range height {
+		for x := range width {
+			magnitude, angle := f.gradientAt(channels[0], x, y)
+
+			for _, channel := range channels[1:] {
+				channelMagnitude, channelAngle := f.gradientAt(channel, x, y)
+
+				if channelMagnitude > magnitude {
+					magnitude, angle = channelMagnitude, channelAngle
+				}
+			}
+
+			g.Magnitude.Set(x, y, magnitude)
+			g.Angle.Set(x, y, angle)
+		}
+	}
+
+	return g
+}
+
+func (f *HOG) gradientAt(p Plane, x, y int) (float32, float32) {
+	var Gx, Gy float32
+
+	width, height := p.Width, p.Height
+
+	// Condition for axis 0
+	if x-1 <= 0 {
+		// Condition if first element. Windows narrower than three pixels
+		// have no right neighbour either, which counts as zero
+		if x+1 < width {
+			Gx = p.At(x+1, y) - 0
+		}
+	} else if x+1 >= width {
+		Gx = 0 - p.At(x-1, y)
+	} else {
+		Gx = p.At(x+1, y) - p.At(x-1, y)
+	}
+
+	// Condition for axis 1
+	if y-1 <= 0 {
+		if y+1 < height {
+			Gy = 0 - p.At(x, y+1)
+		}
+	} else if y+1 >= height {
+		Gy = p.At(x, y-1) - 0
+	} else {
+		Gy = p.At(x, y-1) - p.At(x, y+1)
+	}
+
+	magnitude := math.Round(math.Sqrt(math.Pow(float64(Gx), 2)+math.Pow(float64(Gy), 2))*1e9) / 1e9
+
+	var angle float64
+
+	if Gx != 0 || Gy != 0 {
+		angle = math.Round(math.Atan2(float64(Gy), float64(Gx))*180/math.Pi*1e9) / 1e9
+	}
+
+	// Fold into [0, 360) or [0, 180) depending on the orientation mode
+	angle = math.Mod(angle, float64(f.orientationRange()))
+	if angle < 0 {
+		angle += float64(f.orientationRange())
+	}
+
+	return float32(magnitude), float32(angle)
+}
+
+// Histogram builds the grid of cell histograms. Pixels beyond the last whole
+// cell are ignored.
+func (f *HOG) Histogram(g Gradients) Grid {
+	hist := NewGrid(g.Magnitude.Height/f.cellSize, g.Magnitude.Width/f.cellSize, f.numberOfBins)
+
+	f.histogramInto(g, hist)
+
+	return hist
+}
+
+func (f *HOG) histogramInto(g Gradients, hist Grid) {
+	clear(hist.Data)
+
+	if f.semantics == SemanticsSkimage {
+		f.skimageHistogramInto(g, hist)
+
+		return
+	}
+
+	if f.spatial {
+		f.trilinearInto(g, hist)
+
+		return
+	}
+
+	step := f.cellSize
+
+	for i := range hist.Rows {
+		for j := range hist.Cols {
+			// Like BuildBin and the reference in py/app.py, which reset the
+			// bins for every pixel, only the last pixel of the cell votes
+			y := (i+1)*step - 1
+			x := (j+1)*step - 1
+
+			f.vote(hist.At(i, j), g.Magnitude.At(x, y), g.Angle.At(x, y), 1)
+		}
+	}
+}
+
+func (f *HOG) trilinearInto(g Gradients, hist Grid) {
+	for y := range hist.Rows * f.cellSize {
+		cellsY, weightsY := f.locateCell(y)
+
+		for x := range hist.Cols * f.cellSize {
+			cellsX, weightsX := f.locateCell(x)
+
+			magnitude, angle := g.Magnitude.At(x, y), g.Angle.At(x, y)
+
+			for a := range 2 {
+				if cellsY[a] < 0 || cellsY[a] >= hist.Rows {
+					continue
+				}
+
+				for b := range 2 {
+					if cellsX[b] < 0 || cellsX[b] >= hist.Cols {
+						continue
+					}
+
+					f.vote(hist.At(cellsY[a], cellsX[b]), magnitude, angle, weightsY[a]*weightsX[b])
+				}
+			}
+		}
+	}
+}
+
+// vote splits a pixel's weighted magnitude between the two bins whose centres
+// surround its angle. Bins are circular so the first and last bins are
+// neighbours.
+func (f *HOG) vote(bins []float32, magnitude, angle, weight float32) {
+	valueJ, Vj, Vj_1 := f.BuildRow(magnitude, angle)
+
+	n := f.numberOfBins
+
+	bins[((valueJ%n)+n)%n] += weight * Vj
+	bins[(((valueJ+1)%n)+n)%n] += weight * Vj_1
+}
+
+// locateCell returns the two cells whose centres surround pixel p along one
+// axis together with the share of the vote each of them receives.
+func (f *HOG) locateCell(p int) ([2]int, [2]float32) {
+	c := (float32(p)+0.5)/float32(f.cellSize) - 0.5
+	c0 := float32(math.Floor(float64(c)))
+	w := c - c0
+
+	return [2]int{int(c0), int(c0) + 1}, [2]float32{1 - w, w}
+}
+
+func (f *HOG) blockCount(cellRows, cellCols int) (int, int) {
+	if cellRows < f.blockSize || cellCols < f.blockSize {
+		return 0, 0
+	}
+
+	return (cellRows-f.blockSize)/f.blockStride + 1, (cellCols-f.blockSize)/f.blockStride + 1
+}
+
+// Blocks groups the cell histograms into overlapping blocks and normalizes
+// each of them. Every block's cells are stored row by row.
+func (f *HOG) Blocks(hist Grid) Grid {
+	rows, cols := f.blockCount(hist.Rows, hist.Cols)
+
+	blocks := NewGrid(rows, cols, f.blockSize*f.blockSize*f.numberOfBins)
+
+	for i := range rows {
+		for j := range cols {
+			block := blocks.At(i, j)
+			cells := hist.SubGrid(i*f.blockStride, j*f.blockStride, f.blockSize, f.blockSize)
+
+			for k := range f.blockSize {
+				copy(block[k*f.blockSize*f.numberOfBins:], cells.Row(k))
+			}
+
+			f.normalize(block)
+		}
+	}
+
+	return blocks
+}
+
+func (f *HOG) blockWeights() Plane {
+	size := f.blockSize * f.cellSize
+	sigma := float64(size) / 2
+	centre := float64(size) / 2
+
+	weights := NewPlane(size, size)
+
+	for y := range size {
+		dy := float64(y) + 0.5 - centre
+
+		for x := range size {
+			dx := float64(x) + 0.5 - centre
+
+			weights.Set(x, y, float32(math.Exp(-(dx*dx+dy*dy)/(2*sigma*sigma))))
+		}
+	}
+
+	return weights
+}
+
+// WeightedBlocks builds the normalized blocks from the gradients directly,
+// weighting every pixel's vote by a Gaussian centred on its block.
+func (f *HOG) WeightedBlocks(g Gradients) Grid {
+	weights := f.blockWeights()
+	size := weights.Width
+
+	rows, cols := f.blockCount(g.Magnitude.Height/f.cellSize, g.Magnitude.Width/f.cellSize)
+
+	blocks := NewGrid(rows, cols, f.blockSize*f.blockSize*f.numberOfBins)
+
+	// Scratch space reused by every block
+	weighted := NewPlane(size, size)
+	cells := NewGrid(f.blockSize, f.blockSize, f.numberOfBins)
+
+	for i := range rows {
+		for j := range cols {
+			region := g.SubGradients(j*f.blockStride*f.cellSize, i*f.blockStride*f.cellSize, size, size)
+
+			for y := range size {
+				for x := range size {
+					weighted.Set(x, y, region.Magnitude.At(x, y)*weights.At(x, y))
+				}
+			}
+
+			// The block's own cells, built from the weighted votes
+			f.weightedInto(Gradients{Magnitude: weighted, Angle: region.Angle}, cells)
+
+			block := blocks.At(i, j)
+
+			copy(block, cells.Data)
+
+			f.normalize(block)
+		}
+	}
+
+	return blocks
+}
+
+// weightedInto fills the cells of one block from its weighted gradients.
+// Without spatial interpolation every pixel votes into its own cell: the
+// last-pixel rule of histogramInto would apply the Gaussian to a single pixel
+// per cell.
+func (f *HOG) weightedInto(g Gradients, cells Grid) {
+	if f.spatial {
+		f.histogramInto(g, cells)
+
+		return
+	}
+
+	clear(cells.Data)
+
+	for y := range cells.Rows * f.cellSize {
+		for x := range cells.Cols * f.cellSize {
+			f.vote(cells.At(y/f.cellSize, x/f.cellSize), g.Magnitude.At(x, y), g.Angle.At(x, y), 1)
+		}
+	}
+}
+
+// normalize applies the configured block normalization to block in place.
+func (f *HOG) normalize(block []float32) {
+	switch f.blockNorm {
+	case NormL2Hys:
+		// L2 followed by clipping at 0.2 and a second L2 pass, as in Dalal-Triggs
+		f.normalizeL2(block)
+
+		for i, x := range block {
+			block[i] = min(x, 0.2)
+		}
+
+		f.normalizeL2(block)
+	case NormL1:
+		f.normalizeL1(block)
+	case NormL1Sqrt:
+		f.normalizeL1(block)
+
+		for i, x := range block {
+			block[i] = float32(math.Sqrt(float64(x)))
+		}
+	case NormL2:
+		if f.semantics == SemanticsSkimage {
+			f.normalizeL2(block)
+
+			return
+		}
+
+		k := f.CalculateK(block)
+
+		for i, x := range block {
+			block[i] = x / (k + float32(f.epsilon))
+		}
+	}
+}
+
+func (f *HOG) normalizeL2(block []float32) {
+	var k float64
+
+	for _, x := range block {
+		k += float64(x) * float64(x)
+	}
+
+	k = math.Sqrt(k + f.epsilon*f.epsilon)
+
+	for i, x := range block {
+		block[i] = float32(float64(x) / k)
+	}
+}
+
+func (f *HOG) normalizeL1(block []float32) {
+	var k float64
+
+	for _, x := range block {
+		k += math.Abs(float64(x))
+	}
+
+	k += f.epsilon
+
+	for i, x := range block {
+		block[i] = float32(float64(x) / k)
+	}
+}
+
+// PlaneToImg renders p as a gray image, multiplying every value by factor.
+func (f *HOG) PlaneToImg(p Plane, factor float32) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, p.Width, p.Height))
+
+	for y := range p.Height {
+		row := img.Pix[y*img.Stride:]
+
+		for x, value := range p.Row(y) {
+			row[x] = uint8(value * factor)
+		}
+	}
+
+	return img
+}
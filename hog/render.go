@@ -0,0 +1,135 @@
+package hog
+
+import (
+	"image"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+type RenderOptions struct {
+	// CellScale is the number of output pixels along each side of a cell. It
+	// defaults to the cell size, so the glyphs line up with the window.
+	CellScale int
+	// Width and Height resize the rendered glyphs with the configured
+	// resampler. If only one is set the other keeps the aspect ratio; if
+	// neither is, the output is CellScale pixels per cell.
+	Width  int
+	Height int
+}
+
+// Render draws the classic HOG visualization of a grid of cell histograms:
+// for every bin of every cell, a line through the cell's centre along the
+// edge orientation the bin stands for, so perpendicular to its gradient
+// direction, with a brightness proportional to the bin's weight. Lines of a
+// cell add up and the brightest pixel of the result is white.
+func (f *HOG) Render(hist Grid, opts RenderOptions) *image.Gray {
+	scale := opts.CellScale
+	if scale <= 0 {
+		scale = f.cellSize
+	}
+
+	canvas := NewPlane(hist.Cols*scale, hist.Rows*scale)
+
+	radius := float64(scale)/2 - 1
+
+	// Edge direction of every bin in image coordinates, where y grows down
+	// while the gradient angles are measured with y growing up
+	dx := make([]float64, hist.Depth)
+	dy := make([]float64, hist.Depth)
+
+	for bin := range hist.Depth {
+		theta := float64(f.stepSize) * (float64(bin) + 0.5) * math.Pi / 180
+
+		// skimage measures its angles with y growing down
+		if f.semantics == SemanticsSkimage {
+			theta = -theta
+		}
+
+		dx[bin] = radius * math.Sin(theta)
+		dy[bin] = radius * math.Cos(theta)
+	}
+
+	for i := range hist.Rows {
+		for j := range hist.Cols {
+			centreX := float64(j*scale + scale/2)
+			centreY := float64(i*scale + scale/2)
+
+			for bin, weight := range hist.At(i, j) {
+				if weight == 0 {
+					continue
+				}
+
+				drawLine(canvas,
+					int(math.Round(centreX-dx[bin])), int(math.Round(centreY-dy[bin])),
+					int(math.Round(centreX+dx[bin])), int(math.Round(centreY+dy[bin])),
+					weight)
+			}
+		}
+	}
+
+	var peak float32
+	for _, value := range canvas.Data {
+		peak = max(peak, value)
+	}
+
+	factor := float32(0)
+	if peak > 0 {
+		factor = 255 / peak
+	}
+
+	img := f.PlaneToImg(canvas, factor)
+
+	width, height := opts.Width, opts.Height
+
+	switch {
+	case width <= 0 && height <= 0:
+		return img
+	case canvas.Width == 0 || canvas.Height == 0:
+		// An empty grid has no aspect ratio to keep nor pixels to scale
+		return img
+	case width <= 0:
+		width = int(math.Round(float64(height) * float64(canvas.Width) / float64(canvas.Height)))
+	case height <= 0:
+		height = int(math.Round(float64(width) * float64(canvas.Height) / float64(canvas.Width)))
+	}
+
+	if width == canvas.Width && height == canvas.Height {
+		return img
+	}
+
+	resized := image.NewGray(image.Rect(0, 0, width, height))
+
+	f.resampler.interpolator().Scale(resized, resized.Rect, img, img.Rect, draw.Src, nil)
+
+	return resized
+}
+
+// drawLine adds value to every pixel of the line from (x0, y0) to (x1, y1)
+// that lies inside p, visiting each pixel once.
+func drawLine(p Plane, x0, y0, x1, y1 int, value float32) {
+	steps := max(abs(x1-x0), abs(y1-y0))
+
+	for k := range steps + 1 {
+		x, y := x0, y0
+
+		if steps > 0 {
+			x = x0 + int(math.Round(float64(k*(x1-x0))/float64(steps)))
+			y = y0 + int(math.Round(float64(k*(y1-y0))/float64(steps)))
+		}
+
+		if x < 0 || y < 0 || x >= p.Width || y >= p.Height {
+			continue
+		}
+
+		p.Data[y*p.Stride+x] += value
+	}
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
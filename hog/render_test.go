@@ -0,0 +1,88 @@
+package hog_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/kachaje/hog/hog"
+)
+
+func TestRender(t *testing.T) {
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hist := hog.NewGrid(1, 1, 9)
+
+	// A near-vertical edge at 10 degrees crossing a horizontal one at 90
+	hist.At(0, 0)[0] = 1
+	hist.At(0, 0)[4] = 0.5
+
+	img := f.Render(hist, hog.RenderOptions{})
+
+	if img.Bounds() != image.Rect(0, 0, 8, 8) {
+		t.Fatalf("Test failed. Expected: 8x8; Actual: %v", img.Bounds())
+	}
+
+	target := map[image.Point]uint8{
+		{3, 1}: 170, {3, 2}: 170, {4, 3}: 170, {4, 5}: 170, {5, 6}: 170, {5, 7}: 170,
+		{1, 4}: 85, {2, 4}: 85, {3, 4}: 85, {5, 4}: 85, {6, 4}: 85, {7, 4}: 85,
+		{4, 4}: 255,
+	}
+
+	for y := range 8 {
+		for x := range 8 {
+			result := img.GrayAt(x, y).Y
+
+			if result != target[image.Point{x, y}] {
+				t.Fatalf("Test failed at %v,%v. Expected: %v; Actual: %v", x, y, target[image.Point{x, y}], result)
+			}
+		}
+	}
+}
+
+func TestRenderSize(t *testing.T) {
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hist := hog.NewGrid(4, 2, 9)
+	hist.At(1, 1)[3] = 1
+
+	for _, row := range []struct {
+		opts   hog.RenderOptions
+		target image.Rectangle
+	}{
+		{hog.RenderOptions{}, image.Rect(0, 0, 16, 32)},
+		{hog.RenderOptions{CellScale: 20}, image.Rect(0, 0, 40, 80)},
+		{hog.RenderOptions{Width: 64}, image.Rect(0, 0, 64, 128)},
+		{hog.RenderOptions{Height: 8}, image.Rect(0, 0, 4, 8)},
+		{hog.RenderOptions{CellScale: 4, Width: 10, Height: 10}, image.Rect(0, 0, 10, 10)},
+	} {
+		img := f.Render(hist, row.opts)
+
+		if img.Bounds() != row.target {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", row.target, img.Bounds())
+		}
+	}
+
+	// An empty histogram renders black instead of dividing by zero
+	img := f.Render(hog.NewGrid(2, 2, 9), hog.RenderOptions{})
+
+	for _, value := range img.Pix {
+		if value != 0 {
+			t.Fatalf("Test failed. Expected: 0; Actual: %v", value)
+		}
+	}
+
+	// and an empty grid stays empty whatever the requested size
+	for _, opts := range []hog.RenderOptions{{Width: 64}, {Height: 64}, {Width: 64, Height: 64}} {
+		img := f.Render(hog.NewGrid(0, 0, 9), opts)
+
+		if !img.Bounds().Empty() {
+			t.Fatalf("Test failed. Expected an empty image; Actual: %v", img.Bounds())
+		}
+	}
+}
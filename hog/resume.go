@@ -0,0 +1,247 @@
+package hog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Stage names an intermediate result the pipeline can be resumed from.
+type Stage int
+
+const (
+	// StagePixels starts from the gray intensities of the fitted window,
+	// before compression, as in dump.json.
+	StagePixels Stage = iota
+	// StageGradients starts from the magnitude and angle planes, as in
+	// magnitudes.json and thetas.json.
+	StageGradients
+	// StageHistogram starts from the cell histograms, as in hist.json.
+	StageHistogram
+)
+
+func (s Stage) String() string {
+	switch s {
+	case StagePixels:
+		return "pixels"
+	case StageGradients:
+		return "gradients"
+	case StageHistogram:
+		return "hist"
+	default:
+		return fmt.Sprintf("Stage(%d)", int(s))
+	}
+}
+
+// ParseStage returns the stage with the given name. The names of the stage
+// dumps, dump and histogram, are accepted as well.
+func ParseStage(name string) (Stage, error) {
+	switch name {
+	case "pixels", "dump":
+		return StagePixels, nil
+	case "gradients":
+		return StageGradients, nil
+	case "hist", "histogram":
+		return StageHistogram, nil
+	default:
+		return 0, fmt.Errorf("unknown stage %q", name)
+	}
+}
+
+// Resume runs the pipeline from stage onwards, taking that stage's input from
+// trace (Pixels, Magnitude and Angle, or Histogram) and filling in every
+// later stage. Only Resized and Gray are left empty. Planes larger than the
+// window are cropped to its top-left corner, like MagnitudeTheta does;
+// smaller planes and histograms that do not match the window's cells are an
+// error. The input is not modified.
+func (f *HOG) Resume(stage Stage, trace *Trace) error {
+	var gradients Gradients
+
+	switch stage {
+	case StagePixels:
+		if trace.Pixels.Width == 0 || trace.Pixels.Height == 0 {
+			return fmt.Errorf("trace has no pixels to resume from")
+		}
+
+		if f.colour {
+			return fmt.Errorf("colour gradients cannot be resumed from gray pixels")
+		}
+
+		window, err := f.crop("pixels", trace.Pixels)
+		if err != nil {
+			return err
+		}
+
+		pixels := NewPlane(window.Width, window.Height)
+		for y := range pixels.Height {
+			copy(pixels.Row(y), window.Row(y))
+		}
+
+		f.CompressPlane(pixels)
+
+		gradients = f.Gradients(pixels)
+	case StageGradients:
+		if trace.Magnitude.Width == 0 || trace.Magnitude.Height == 0 {
+			return fmt.Errorf("trace has no gradients to resume from")
+		}
+
+		if trace.Magnitude.Width != trace.Angle.Width || trace.Magnitude.Height != trace.Angle.Height {
+			return fmt.Errorf("magnitudes are %dx%d but angles are %dx%d", trace.Magnitude.Width, trace.Magnitude.Height, trace.Angle.Width, trace.Angle.Height)
+		}
+
+		magnitude, err := f.crop("magnitudes", trace.Magnitude)
+		if err != nil {
+			return err
+		}
+
+		angle, err := f.crop("angles", trace.Angle)
+		if err != nil {
+			return err
+		}
+
+		gradients = Gradients{Magnitude: magnitude, Angle: angle}
+	case StageHistogram:
+		if trace.Histogram.Rows == 0 || trace.Histogram.Cols == 0 {
+			return fmt.Errorf("trace has no histograms to resume from")
+		}
+
+		if f.gaussian {
+			return fmt.Errorf("gaussian weighting cannot be resumed from cell histograms")
+		}
+
+		if trace.Histogram.Depth != f.numberOfBins {
+			return fmt.Errorf("histograms have %d bins, expected %d", trace.Histogram.Depth, f.numberOfBins)
+		}
+
+		if rows, cols := f.height/f.cellSize, f.width/f.cellSize; trace.Histogram.Rows != rows || trace.Histogram.Cols != cols {
+			return fmt.Errorf("histograms are %dx%d cells, expected %dx%d", trace.Histogram.Rows, trace.Histogram.Cols, rows, cols)
+		}
+	default:
+		return fmt.Errorf("unknown stage %d", stage)
+	}
+
+	if stage < StageGradients {
+		trace.Magnitude = gradients.Magnitude
+		trace.Angle = gradients.Angle
+	}
+
+	if stage < StageHistogram {
+		trace.Histogram = f.Histogram(gradients)
+	}
+
+	if f.gaussian {
+		trace.Blocks = f.WeightedBlocks(gradients)
+	} else {
+		trace.Blocks = f.Blocks(trace.Histogram)
+	}
+
+	trace.Features = trace.Blocks.Flatten()
+	trace.Image = f.Render(trace.Histogram, RenderOptions{})
+
+	return nil
+}
+
+// crop returns the window-sized top-left corner of p, or an error naming p
+// if it is smaller than the window.
+func (f *HOG) crop(name string, p Plane) (Plane, error) {
+	if p.Width < f.width || p.Height < f.height {
+		return Plane{}, fmt.Errorf("%s are %dx%d, smaller than the %dx%d window", name, p.Width, p.Height, f.width, f.height)
+	}
+
+	return p.SubPlane(0, 0, f.width, f.height), nil
+}
+
+// ReadPlane decodes a JSON array of rows, such as dump.json or
+// magnitudes.json.
+func ReadPlane(r io.Reader) (Plane, error) {
+	var rows [][]float32
+
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return Plane{}, err
+	}
+
+	for y, row := range rows {
+		if len(row) != len(rows[0]) {
+			return Plane{}, fmt.Errorf("row %d has %d values, expected %d", y, len(row), len(rows[0]))
+		}
+	}
+
+	return PlaneFromRows(rows), nil
+}
+
+// ReadGrid decodes a JSON array of rows of vectors, such as hist.json.
+func ReadGrid(r io.Reader) (Grid, error) {
+	var data [][][]float32
+
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return Grid{}, err
+	}
+
+	for i := range data {
+		if len(data[i]) != len(data[0]) {
+			return Grid{}, fmt.Errorf("row %d has %d vectors, expected %d", i, len(data[i]), len(data[0]))
+		}
+
+		for j := range data[i] {
+			if len(data[i][j]) != len(data[0][0]) {
+				return Grid{}, fmt.Errorf("vector %d,%d has %d values, expected %d", i, j, len(data[i][j]), len(data[0][0]))
+			}
+		}
+	}
+
+	return GridFromNested(data), nil
+}
+
+// LoadStage reads the input of stage from JSON files: one file for pixels or
+// histograms, and a magnitude file followed by an angle file for gradients.
+func LoadStage(stage Stage, paths ...string) (*Trace, error) {
+	want := 1
+	if stage == StageGradients {
+		want = 2
+	}
+
+	if len(paths) != want {
+		return nil, fmt.Errorf("stage %v reads %d file(s), got %d", stage, want, len(paths))
+	}
+
+	trace := &Trace{}
+
+	var err error
+
+	switch stage {
+	case StagePixels:
+		trace.Pixels, err = readFile(paths[0], ReadPlane)
+	case StageGradients:
+		trace.Magnitude, err = readFile(paths[0], ReadPlane)
+		if err == nil {
+			trace.Angle, err = readFile(paths[1], ReadPlane)
+		}
+	case StageHistogram:
+		trace.Histogram, err = readFile(paths[0], ReadGrid)
+	default:
+		err = fmt.Errorf("unknown stage %d", stage)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return trace, nil
+}
+
+func readFile[T any](path string, read func(io.Reader) (T, error)) (T, error) {
+	reader, err := os.Open(path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer reader.Close()
+
+	value, err := read(reader)
+	if err != nil {
+		return value, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,210 @@
+package hog_test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kachaje/hog/hog"
+)
+
+func TestResume(t *testing.T) {
+	img := loadFlower(t)
+
+	for _, opts := range [][]hog.Option{
+		nil,
+		{hog.WithCompression(hog.CompressionGamma, 0.5), hog.WithSpatialInterpolation(true)},
+		{hog.WithGaussianWeighting(true), hog.WithBlockNorm(hog.NormL2Hys)},
+	} {
+		f, err := hog.NewHOG(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		target, err := f.Trace(img)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stages := []hog.Stage{hog.StagePixels, hog.StageGradients}
+		if !f.Config().GaussianWeighting {
+			stages = append(stages, hog.StageHistogram)
+		}
+
+		for _, stage := range stages {
+			trace := &hog.Trace{
+				Pixels:    target.Pixels,
+				Magnitude: target.Magnitude,
+				Angle:     target.Angle,
+				Histogram: target.Histogram,
+			}
+
+			if err := f.Resume(stage, trace); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(trace.Features, target.Features) {
+				t.Fatalf("Test failed. Expected resuming from %v to reproduce the features", stage)
+			}
+
+			if !reflect.DeepEqual(trace.Image, target.Image) {
+				t.Fatalf("Test failed. Expected resuming from %v to reproduce the image", stage)
+			}
+		}
+
+		// Compression must not leak back into the traced pixels
+		if !reflect.DeepEqual(target.Pixels, f.Pixels(target.Resized)) {
+			t.Fatal("Test failed. Input was modified")
+		}
+	}
+}
+
+func TestResumeFixtures(t *testing.T) {
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trace, err := hog.LoadStage(hog.StageHistogram, filepath.Join(".", "fixtures", "hist.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Resume(hog.StageHistogram, trace); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := os.Open(filepath.Join(".", "fixtures", "features.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	target, err := hog.ReadGrid(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual([]int{trace.Blocks.Rows, trace.Blocks.Cols, trace.Blocks.Depth}, []int{15, 7, 36}) {
+		t.Fatalf("Test failed. Expected: 15x7x36; Actual: %vx%vx%v", trace.Blocks.Rows, trace.Blocks.Cols, trace.Blocks.Depth)
+	}
+
+	for i, value := range target.Flatten() {
+		if math.Floor(float64(value)*1e3) != math.Floor(float64(trace.Features[i])*1e3) {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", value, trace.Features[i])
+		}
+	}
+
+	trace, err = hog.LoadStage(hog.StageGradients, filepath.Join(".", "fixtures", "magnitudes.json"), filepath.Join(".", "fixtures", "thetas.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Resume(hog.StageGradients, trace); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(trace.Features) != 3780 {
+		t.Fatalf("Test failed. Expected: 3780; Actual: %v", len(trace.Features))
+	}
+
+	// dump.json holds the whole image, of which the window is the top-left
+	// corner, like MagnitudeTheta
+	trace, err = hog.LoadStage(hog.StagePixels, filepath.Join(".", "fixtures", "dump.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	window := &hog.Trace{Pixels: trace.Pixels.SubPlane(0, 0, 64, 128)}
+
+	for _, trace := range []*hog.Trace{trace, window} {
+		if err := f.Resume(hog.StagePixels, trace); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(trace.Features) != 3780 || !reflect.DeepEqual(trace.Features, window.Features) {
+		t.Fatalf("Test failed. Expected the features of the 64x128 corner; Actual: %v features", len(trace.Features))
+	}
+
+	if trace.Magnitude.Width != 64 || trace.Magnitude.Height != 128 {
+		t.Fatalf("Test failed. Expected: 64x128; Actual: %vx%v", trace.Magnitude.Width, trace.Magnitude.Height)
+	}
+
+	magnitudes, angles := f.MagnitudeTheta(trace.Pixels.Rows())
+
+	if !reflect.DeepEqual(trace.Magnitude.Rows(), magnitudes) || !reflect.DeepEqual(trace.Angle.Rows(), angles) {
+		t.Fatal("Test failed. Expected the gradients of MagnitudeTheta")
+	}
+
+	// Gradients larger than the window are cropped the same way
+	gradients := &hog.Trace{Magnitude: hog.NewPlane(640, 427), Angle: hog.NewPlane(640, 427)}
+
+	if err := f.Resume(hog.StageGradients, gradients); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gradients.Features) != 3780 {
+		t.Fatalf("Test failed. Expected: 3780; Actual: %v", len(gradients.Features))
+	}
+}
+
+func TestResumeErrors(t *testing.T) {
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	colour, err := hog.NewHOG(hog.WithColour(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pixels := hog.NewPlane(64, 128)
+
+	for _, row := range []struct {
+		f     *hog.HOG
+		stage hog.Stage
+		trace *hog.Trace
+	}{
+		{f, hog.StagePixels, &hog.Trace{}},
+		{colour, hog.StagePixels, &hog.Trace{Pixels: pixels}},
+		{f, hog.StageGradients, &hog.Trace{Magnitude: pixels, Angle: hog.NewPlane(64, 64)}},
+		{f, hog.StageHistogram, &hog.Trace{Histogram: hog.NewGrid(16, 8, 12)}},
+		{f, hog.StagePixels, &hog.Trace{Pixels: hog.NewPlane(64, 64)}},
+		{f, hog.StageGradients, &hog.Trace{Magnitude: hog.NewPlane(32, 128), Angle: hog.NewPlane(32, 128)}},
+		{f, hog.StageHistogram, &hog.Trace{Histogram: hog.NewGrid(15, 8, 9)}},
+		{f, hog.StageHistogram, &hog.Trace{Histogram: hog.NewGrid(54, 80, 9)}},
+		{f, hog.Stage(7), &hog.Trace{}},
+	} {
+		if err := row.f.Resume(row.stage, row.trace); err == nil {
+			t.Fatalf("Test failed. Expected an error resuming from %v", row.stage)
+		}
+	}
+
+	if _, err := hog.LoadStage(hog.StageGradients, filepath.Join(".", "fixtures", "magnitudes.json")); err == nil {
+		t.Fatal("Test failed. Expected an error for a missing angle file")
+	}
+
+	if _, err := hog.ReadPlane(strings.NewReader("[[1, 2], [3]]")); err == nil {
+		t.Fatal("Test failed. Expected an error for ragged rows")
+	}
+
+	for _, name := range []string{"pixels", "dump", "gradients", "hist", "histogram"} {
+		stage, err := hog.ParseStage(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := hog.ParseStage(stage.String()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := hog.ParseStage("blocks"); err == nil {
+		t.Fatal("Test failed. Expected an error for an unknown stage")
+	}
+}
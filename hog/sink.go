@@ -0,0 +1,139 @@
+package hog
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/jpeg"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// Sink receives the intermediate artifacts of a debug run, such as the
+// resized input or the cell histograms, each under a file name.
+type Sink interface {
+	Write(name string, data []byte) error
+}
+
+// DirSink writes every artifact as a file in Dir, creating it if needed.
+type DirSink struct {
+	Dir string
+}
+
+func (s DirSink) Write(name string, data []byte) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(s.Dir, name), data, 0644)
+}
+
+// NewRunSink creates a new uniquely named subdirectory of parent and returns
+// a sink writing into it, so concurrent runs never overwrite each other.
+func NewRunSink(parent string) (DirSink, error) {
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return DirSink{}, err
+	}
+
+	dir, err := os.MkdirTemp(parent, "run-")
+	if err != nil {
+		return DirSink{}, err
+	}
+
+	return DirSink{Dir: dir}, nil
+}
+
+// ZipSink adds every artifact as an entry of a zip archive. Close must be
+// called to finish the archive. It is safe for concurrent use.
+type ZipSink struct {
+	mu     sync.Mutex
+	writer *zip.Writer
+}
+
+func NewZipSink(w io.Writer) *ZipSink {
+	return &ZipSink{writer: zip.NewWriter(w)}
+}
+
+func (s *ZipSink) Write(name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, err := s.writer.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = entry.Write(data)
+
+	return err
+}
+
+// Close writes the archive's directory. It does not close the underlying
+// writer.
+func (s *ZipSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.writer.Close()
+}
+
+// MemorySink keeps every artifact in memory. It is safe for concurrent use.
+type MemorySink struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{files: map[string][]byte{}}
+}
+
+func (s *MemorySink) Write(name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.files[name] = bytes.Clone(data)
+
+	return nil
+}
+
+// Get returns the artifact written under name and whether there was one.
+func (s *MemorySink) Get(name string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.files[name]
+
+	return data, ok
+}
+
+// Names returns the names of all artifacts written so far, sorted.
+func (s *MemorySink) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Sorted(maps.Keys(s.files))
+}
+
+func writeJPEG(sink Sink, name string, img image.Image) error {
+	var buffer bytes.Buffer
+
+	if err := jpeg.Encode(&buffer, img, nil); err != nil {
+		return err
+	}
+
+	return sink.Write(name, buffer.Bytes())
+}
+
+func writeJSON(sink Sink, name string, value any) error {
+	payload, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return sink.Write(name, payload)
+}
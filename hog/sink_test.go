@@ -0,0 +1,179 @@
+package hog_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"image"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kachaje/hog/hog"
+)
+
+var artifacts = []string{
+	"outputAngles.json",
+	"outputDump.json",
+	"outputFeatures.json",
+	"outputGray.jpg",
+	"outputHOG.jpg",
+	"outputHist.json",
+	"outputMagnitudes.json",
+	"outputResized.jpg",
+}
+
+func loadFlower(t *testing.T) image.Image {
+	reader, err := os.Open(filepath.Join("..", "data", "flower.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	img, _, err := image.Decode(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return img
+}
+
+func TestMemorySink(t *testing.T) {
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sink := hog.NewMemorySink()
+
+	_, features, err := f.HOG(loadFlower(t), sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(sink.Names(), artifacts) {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", artifacts, sink.Names())
+	}
+
+	payload, ok := sink.Get("outputFeatures.json")
+	if !ok {
+		t.Fatal("Test failed. Expected the features to be written")
+	}
+
+	var result []float32
+
+	if err := json.Unmarshal(payload, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(result, features) {
+		t.Fatal("Test failed. Expected the written features to match the returned ones")
+	}
+}
+
+func TestDirSinks(t *testing.T) {
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent := t.TempDir()
+
+	first, err := hog.NewRunSink(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := hog.NewRunSink(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Dir == second.Dir {
+		t.Fatalf("Test failed. Expected separate run directories; Actual: %v", first.Dir)
+	}
+
+	img := loadFlower(t)
+
+	for _, sink := range []hog.DirSink{first, second, {Dir: filepath.Join(parent, "nested", "dir")}} {
+		if _, _, err := f.HOG(img, sink); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, name := range artifacts {
+			if _, err := os.Stat(filepath.Join(sink.Dir, name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestZipSink(t *testing.T) {
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+
+	sink := hog.NewZipSink(&buffer)
+
+	if _, _, err := f.HOG(loadFlower(t), sink); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(archive.File) != len(artifacts) {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", len(artifacts), len(archive.File))
+	}
+}
+
+type failingSink struct {
+	failOn string
+}
+
+var errSink = errors.New("disk full")
+
+func (s failingSink) Write(name string, data []byte) error {
+	if name == s.failOn {
+		return errSink
+	}
+
+	return nil
+}
+
+func TestSinkErrors(t *testing.T) {
+	f, err := hog.NewHOG()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := loadFlower(t)
+
+	for _, name := range artifacts {
+		if _, _, err := f.HOG(img, failingSink{name}); !errors.Is(err, errSink) {
+			t.Fatalf("Test failed on %v. Expected: %v; Actual: %v", name, errSink, err)
+		}
+	}
+
+	// A file in the way of the directory
+	blocker := filepath.Join(t.TempDir(), "blocker")
+
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := f.HOG(img, hog.DirSink{Dir: blocker}); err == nil {
+		t.Fatal("Test failed. Expected an error writing into a file")
+	}
+}
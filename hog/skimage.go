@@ -0,0 +1,247 @@
+package hog
+
+import (
+	"image"
+	"math"
+)
+
+// Weights of skimage.color.rgb2gray, from ITU-R BT.709.
+const (
+	skimageRed   = 0.2125
+	skimageGreen = 0.7154
+	skimageBlue  = 0.0721
+)
+
+// plane64 is a plane of float64 values. The skimage semantics keep gray
+// levels and differences in float64, as numpy does, because a float32
+// difference turns angles such as 45 degrees into their neighbours and moves
+// them across a bin edge.
+type plane64 struct {
+	width  int
+	height int
+	data   []float64
+}
+
+func (p plane64) at(x, y int) float64 {
+	return p.data[y*p.width+x]
+}
+
+// newPlane64 widens a float32 plane, such as resumed pixels.
+func newPlane64(p Plane) plane64 {
+	result := plane64{width: p.Width, height: p.Height, data: make([]float64, 0, p.Width*p.Height)}
+
+	for y := range p.Height {
+		for _, value := range p.Row(y) {
+			result.data = append(result.data, float64(value))
+		}
+	}
+
+	return result
+}
+
+// skimagePixels converts img to gray levels in [0, 1] like img_as_float for
+// gray images and rgb2gray for colour ones.
+func (f *HOG) skimagePixels(img image.Image) Plane {
+	gray := f.skimageGray(img)
+
+	p := NewPlane(gray.width, gray.height)
+
+	for i, value := range gray.data {
+		p.Data[i] = float32(value)
+	}
+
+	return p
+}
+
+func (f *HOG) skimageGray(img image.Image) plane64 {
+	bounds := img.Bounds()
+	p := plane64{width: bounds.Dx(), height: bounds.Dy(), data: make([]float64, bounds.Dx()*bounds.Dy())}
+
+	switch src := img.(type) {
+	case *image.Gray:
+		for y := range p.height {
+			offset := src.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+			row := p.data[y*p.width : (y+1)*p.width]
+
+			for x := range row {
+				row[x] = float64(src.Pix[offset+x]) / 255
+			}
+		}
+	case *image.RGBA:
+		for y := range p.height {
+			offset := src.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+			row := p.data[y*p.width : (y+1)*p.width]
+
+			for x := range row {
+				pix := src.Pix[offset+4*x:]
+
+				row[x] = skimageLuma(float64(pix[0]), float64(pix[1]), float64(pix[2])) / 255
+			}
+		}
+	default:
+		for y := range p.height {
+			row := p.data[y*p.width : (y+1)*p.width]
+
+			for x := range row {
+				r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+
+				row[x] = skimageLuma(float64(r), float64(g), float64(b)) / 0xffff
+			}
+		}
+	}
+
+	return p
+}
+
+// skimageLuma weighs a colour like rgb2gray. The fitted window is always
+// RGBA, so a gray pixel keeps its level: the weights do not sum to exactly 1
+// in float64 and would move a fifth of the gray levels by one ulp.
+func skimageLuma(r, g, b float64) float64 {
+	if r == g && g == b {
+		return r
+	}
+
+	return skimageRed*r + skimageGreen*g + skimageBlue*b
+}
+
+// skimageChannels returns the compressed float64 planes the gradients of img
+// are computed from: the gray levels, or red, green and blue in colour.
+func (f *HOG) skimageChannels(img image.Image) []plane64 {
+	var channels []plane64
+
+	if f.colour {
+		bounds := img.Bounds()
+
+		for range 3 {
+			channels = append(channels, plane64{width: bounds.Dx(), height: bounds.Dy(), data: make([]float64, bounds.Dx()*bounds.Dy())})
+		}
+
+		for y := range bounds.Dy() {
+			for x := range bounds.Dx() {
+				r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+
+				channels[0].data[y*bounds.Dx()+x] = float64(r>>8) / 255
+				channels[1].data[y*bounds.Dx()+x] = float64(g>>8) / 255
+				channels[2].data[y*bounds.Dx()+x] = float64(b>>8) / 255
+			}
+		}
+	} else {
+		channels = []plane64{f.skimageGray(img)}
+	}
+
+	for _, channel := range channels {
+		for i, value := range channel.data {
+			channel.data[i] = f.compress(value)
+		}
+	}
+
+	return channels
+}
+
+// skimageGradients follows skimage's _hog_channel_gradient on every channel:
+// central differences with rows growing downwards, zero on the first and
+// last row and column, and the angle of arctan2(g_row, g_col) folded into
+// [0, 180). Each pixel keeps the channel with the largest magnitude. The
+// float64 angles are kept alongside the planes for the binning.
+func (f *HOG) skimageGradients(channels []plane64) Gradients {
+	width, height := channels[0].width, channels[0].height
+
+	g := NewGradients(width, height)
+	g.angles = make([]float64, width*height)
+
+	for y := range height {
+		for x := range width {
+			magnitude, angle := skimageGradientAt(channels[0], x, y)
+
+			for _, channel := range channels[1:] {
+				channelMagnitude, channelAngle := skimageGradientAt(channel, x, y)
+
+				if channelMagnitude > magnitude {
+					magnitude, angle = channelMagnitude, channelAngle
+				}
+			}
+
+			g.Magnitude.Set(x, y, float32(magnitude))
+			g.Angle.Set(x, y, float32(angle))
+			g.angles[y*width+x] = angle
+		}
+	}
+
+	return g
+}
+
+func skimageGradientAt(p plane64, x, y int) (float64, float64) {
+	var gCol, gRow float64
+
+	if x > 0 && x < p.width-1 {
+		gCol = p.at(x+1, y) - p.at(x-1, y)
+	}
+
+	if y > 0 && y < p.height-1 {
+		gRow = p.at(x, y+1) - p.at(x, y-1)
+	}
+
+	// Same rounding as np.rad2deg, so angles such as 45 degrees land on the
+	// same side of a bin boundary
+	angle := math.Mod(math.Atan2(gRow, gCol)*(180/float64(math.Pi)), 180)
+	if angle < 0 {
+		angle += 180
+	}
+
+	return math.Hypot(gCol, gRow), angle
+}
+
+// skimageHistogramInto follows skimage's hog_histograms: every pixel adds
+// its magnitude to the one bin whose range [start, end) holds its angle,
+// and each cell's bins are divided by the number of pixels in the cell.
+// Gradients from skimageGradients are binned by their float64 angles.
+func (f *HOG) skimageHistogramInto(g Gradients, hist Grid) {
+	step := float64(f.stepSize)
+	area := float64(f.cellSize * f.cellSize)
+
+	sums := make([]float64, f.numberOfBins)
+
+	for i := range hist.Rows {
+		for j := range hist.Cols {
+			clear(sums)
+
+			for y := i * f.cellSize; y < (i+1)*f.cellSize; y++ {
+				magnitudes := g.Magnitude.Row(y)[j*f.cellSize : (j+1)*f.cellSize]
+				angles := g.Angle.Row(y)[j*f.cellSize : (j+1)*f.cellSize]
+
+				var exact []float64
+				if g.angles != nil {
+					exact = g.angles[y*g.Angle.Width+j*f.cellSize : y*g.Angle.Width+(j+1)*f.cellSize]
+				}
+
+				for k := range angles {
+					angle := float64(angles[k])
+					if exact != nil {
+						angle = exact[k]
+					}
+
+					bin := int(angle / step)
+
+					// Division can round across a bin boundary
+					if bin > 0 && angle < step*float64(bin) {
+						bin--
+					} else if angle >= step*float64(bin+1) {
+						bin++
+					}
+
+					if bin < 0 || bin >= f.numberOfBins {
+						continue
+					}
+
+					sums[bin] += float64(magnitudes[k])
+				}
+			}
+
+			cell := hist.At(i, j)
+
+			for bin, sum := range sums {
+				cell[bin] = float32(sum / area)
+			}
+		}
+	}
+}
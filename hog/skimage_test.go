@@ -0,0 +1,219 @@
+package hog_test
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kachaje/hog/hog"
+)
+
+// golden is one reference vector written by py/skimage_golden.py, with the
+// arguments given to skimage.feature.hog.
+type golden struct {
+	Name          string    `json:"name"`
+	Orientations  int       `json:"orientations"`
+	PixelsPerCell [2]int    `json:"pixels_per_cell"`
+	CellsPerBlock [2]int    `json:"cells_per_block"`
+	BlockNorm     string    `json:"block_norm"`
+	TransformSqrt bool      `json:"transform_sqrt"`
+	Features      []float32 `json:"features"`
+}
+
+// goldenFile is golden.json: the vectors and the scikit-image version they
+// were cross-checked with.
+type goldenFile struct {
+	Skimage string   `json:"skimage"`
+	Cases   []golden `json:"cases"`
+}
+
+func loadSkimageFixtures(t *testing.T) (*image.Gray, []golden) {
+	// Gray levels are stored as numbers, which []uint8 would read as base64
+	var pixels [][]int
+
+	data, err := os.ReadFile(filepath.Join(".", "fixtures", "skimage", "image.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := json.Unmarshal(data, &pixels); err != nil {
+		t.Fatal(err)
+	}
+
+	img := image.NewGray(image.Rect(0, 0, len(pixels[0]), len(pixels)))
+
+	for y, row := range pixels {
+		for x, value := range row {
+			img.SetGray(x, y, color.Gray{uint8(value)})
+		}
+	}
+
+	var file goldenFile
+
+	data, err = os.ReadFile(filepath.Join(".", "fixtures", "skimage", "golden.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(file.Cases) == 0 {
+		t.Fatal("golden.json holds no vectors")
+	}
+
+	if file.Skimage == "" {
+		t.Fatal("golden.json was not cross-checked against scikit-image; regenerate it with py/skimage_golden.py")
+	}
+
+	return img, file.Cases
+}
+
+func TestSkimageGolden(t *testing.T) {
+	img, cases := loadSkimageFixtures(t)
+
+	norms := map[string]hog.BlockNorm{
+		"L1":      hog.NormL1,
+		"L1-sqrt": hog.NormL1Sqrt,
+		"L2":      hog.NormL2,
+		"L2-Hys":  hog.NormL2Hys,
+	}
+
+	for _, row := range cases {
+		opts := []hog.Option{
+			hog.WithSkimage(),
+			hog.WithWindow(img.Rect.Dx(), img.Rect.Dy()),
+			hog.WithBins(row.Orientations),
+			hog.WithCellSize(row.PixelsPerCell[0]),
+			hog.WithBlock(row.CellsPerBlock[0], 1),
+			hog.WithBlockNorm(norms[row.BlockNorm]),
+		}
+
+		if row.TransformSqrt {
+			opts = append(opts, hog.WithCompression(hog.CompressionSqrt, 0))
+		}
+
+		f, err := hog.NewHOG(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, features, err := f.HOG(img, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(features) != len(row.Features) || f.FeatureLength() != len(row.Features) {
+			t.Fatalf("Test failed on %v. Expected: %v; Actual: %v", row.Name, len(row.Features), len(features))
+		}
+
+		for i, target := range row.Features {
+			if math.Abs(float64(features[i]-target)) > 1e-5 {
+				t.Fatalf("Test failed on %v at %v. Expected: %v; Actual: %v", row.Name, i, target, features[i])
+			}
+		}
+	}
+}
+
+func TestSkimageConfig(t *testing.T) {
+	f, err := hog.NewHOG(hog.WithSkimage())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := f.Config()
+
+	if config.Bins != 9 || config.CellSize != 8 || config.BlockSize != 3 || config.BlockStride != 1 ||
+		config.BlockNorm != hog.NormL2Hys || config.Semantics != hog.SemanticsSkimage {
+		t.Fatalf("Test failed. Unexpected preset %#v", config)
+	}
+
+	for _, opt := range []hog.Option{
+		hog.WithSigned(true),
+		hog.WithSpatialInterpolation(true),
+		hog.WithGaussianWeighting(true),
+		hog.WithSemantics(hog.Semantics(5)),
+	} {
+		if _, err := hog.NewHOG(hog.WithSkimage(), opt); err == nil {
+			t.Fatal("Test failed. Expected an error for a setting skimage does not have")
+		}
+	}
+}
+
+func TestSkimageGradients(t *testing.T) {
+	f, err := hog.NewHOG(hog.WithSkimage())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rows grow downwards: brighter below gives 90 degrees, brighter to the
+	// left 180 folded to 0, and the border stays zero
+	p := hog.PlaneFromRows([][]float32{
+		{0, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 2, 0, 0},
+		{0, 3, 0, 0},
+	})
+
+	g := f.Gradients(p)
+
+	for _, row := range []struct {
+		x, y             int
+		magnitude, angle float32
+	}{
+		{1, 2, 2, 90},
+		{2, 2, 2, 0},
+		{0, 2, 0, 0},
+		{1, 3, 0, 0},
+		{2, 1, 1, 0},
+	} {
+		magnitude, angle := g.Magnitude.At(row.x, row.y), g.Angle.At(row.x, row.y)
+
+		if magnitude != row.magnitude || angle != row.angle {
+			t.Fatalf("Test failed at %v,%v. Expected: %v, %v; Actual: %v, %v", row.x, row.y, row.magnitude, row.angle, magnitude, angle)
+		}
+	}
+
+	// Gray levels in [0, 1] with rgb2gray weights for colour images
+	rgba := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	rgba.Set(0, 0, color.RGBA{255, 0, 0, 255})
+
+	if value := f.Pixels(rgba).At(0, 0); math.Abs(float64(value)-0.2125) > 1e-6 {
+		t.Fatalf("Test failed. Expected: 0.2125; Actual: %v", value)
+	}
+
+	gray := image.NewGray(image.Rect(0, 0, 1, 1))
+	gray.Pix[0] = 255
+
+	if value := f.Pixels(gray).At(0, 0); value != 1 {
+		t.Fatalf("Test failed. Expected: 1; Actual: %v", value)
+	}
+}
+
+func TestSkimageBinEdge(t *testing.T) {
+	f, err := hog.NewHOG(hog.WithSkimage(), hog.WithWindow(3, 3), hog.WithCellSize(3), hog.WithBlock(1, 1), hog.WithBins(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the centre has a gradient: (1-0)/255 across and (6-5)/255 down,
+	// exactly 45 degrees in float64 but 44.999996 from float32 gray levels
+	img := image.NewGray(image.Rect(0, 0, 3, 3))
+	img.SetGray(2, 1, color.Gray{1})
+	img.SetGray(1, 0, color.Gray{5})
+	img.SetGray(1, 2, color.Gray{6})
+
+	trace, err := f.Trace(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cell := trace.Histogram.At(0, 0); cell[0] != 0 || cell[1] == 0 {
+		t.Fatalf("Test failed. Expected the vote in the 45 degree bin; Actual: %v", cell)
+	}
+}
@@ -0,0 +1,206 @@
+package hog
+
+// Plane is a single-channel 2D array of float32 values stored row-major in a
+// single slice. Stride is the distance between the starts of two rows, which
+// lets a Plane be a view into a larger one without copying.
+type Plane struct {
+	Width  int
+	Height int
+	Stride int
+	Data   []float32
+}
+
+func NewPlane(width, height int) Plane {
+	return Plane{
+		Width:  width,
+		Height: height,
+		Stride: width,
+		Data:   make([]float32, width*height),
+	}
+}
+
+// PlaneFromRows copies nested rows into a new Plane.
+func PlaneFromRows(rows [][]float32) Plane {
+	if len(rows) == 0 {
+		return Plane{}
+	}
+
+	p := NewPlane(len(rows[0]), len(rows))
+
+	for y, row := range rows {
+		copy(p.Row(y), row)
+	}
+
+	return p
+}
+
+func (p Plane) At(x, y int) float32 {
+	return p.Data[y*p.Stride+x]
+}
+
+func (p Plane) Set(x, y int, value float32) {
+	p.Data[y*p.Stride+x] = value
+}
+
+// Row returns row y, sharing memory with the plane.
+func (p Plane) Row(y int) []float32 {
+	start := y * p.Stride
+
+	return p.Data[start : start+p.Width]
+}
+
+// SubPlane returns a width x height view whose top-left corner is at (x, y).
+func (p Plane) SubPlane(x, y, width, height int) Plane {
+	start := y*p.Stride + x
+	end := start + (height-1)*p.Stride + width
+
+	return Plane{
+		Width:  width,
+		Height: height,
+		Stride: p.Stride,
+		Data:   p.Data[start:end],
+	}
+}
+
+// Rows returns the plane as nested rows that share memory with it.
+func (p Plane) Rows() [][]float32 {
+	rows := make([][]float32, p.Height)
+
+	for y := range rows {
+		rows[y] = p.Row(y)
+	}
+
+	return rows
+}
+
+// Gradients holds the per-pixel gradient magnitude and orientation planes.
+type Gradients struct {
+	Magnitude Plane
+	Angle     Plane
+
+	// angles are the float64 orientations behind Angle under the skimage
+	// semantics, which bin edges need, with a stride of Angle.Width
+	angles []float64
+}
+
+// NewGradients allocates both planes from a single backing slice.
+func NewGradients(width, height int) Gradients {
+	data := make([]float32, 2*width*height)
+
+	return Gradients{
+		Magnitude: Plane{Width: width, Height: height, Stride: width, Data: data[:width*height]},
+		Angle:     Plane{Width: width, Height: height, Stride: width, Data: data[width*height:]},
+	}
+}
+
+// SubGradients returns a view of both planes with its top-left corner at
+// (x, y).
+func (g Gradients) SubGradients(x, y, width, height int) Gradients {
+	return Gradients{
+		Magnitude: g.Magnitude.SubPlane(x, y, width, height),
+		Angle:     g.Angle.SubPlane(x, y, width, height),
+	}
+}
+
+// Grid is a Rows x Cols grid of vectors of Depth values, such as the cell
+// histograms or the normalized blocks. Stride is the distance between the
+// starts of two rows of vectors.
+type Grid struct {
+	Rows   int
+	Cols   int
+	Depth  int
+	Stride int
+	Data   []float32
+}
+
+func NewGrid(rows, cols, depth int) Grid {
+	return Grid{
+		Rows:   rows,
+		Cols:   cols,
+		Depth:  depth,
+		Stride: cols * depth,
+		Data:   make([]float32, rows*cols*depth),
+	}
+}
+
+// GridFromNested copies nested vectors into a new Grid.
+func GridFromNested(data [][][]float32) Grid {
+	if len(data) == 0 || len(data[0]) == 0 {
+		return Grid{}
+	}
+
+	g := NewGrid(len(data), len(data[0]), len(data[0][0]))
+
+	for i := range data {
+		for j := range data[i] {
+			copy(g.At(i, j), data[i][j])
+		}
+	}
+
+	return g
+}
+
+// At returns the vector at row i and column j, sharing memory with the grid.
+func (g Grid) At(i, j int) []float32 {
+	start := i*g.Stride + j*g.Depth
+
+	return g.Data[start : start+g.Depth]
+}
+
+// Row returns the vectors of row i back to back, sharing memory with the grid.
+func (g Grid) Row(i int) []float32 {
+	start := i * g.Stride
+
+	return g.Data[start : start+g.Cols*g.Depth]
+}
+
+// SubGrid returns a rows x cols view whose first vector is at (i, j).
+func (g Grid) SubGrid(i, j, rows, cols int) Grid {
+	start := i*g.Stride + j*g.Depth
+	end := start + (rows-1)*g.Stride + cols*g.Depth
+
+	return Grid{
+		Rows:   rows,
+		Cols:   cols,
+		Depth:  g.Depth,
+		Stride: g.Stride,
+		Data:   g.Data[start:end],
+	}
+}
+
+// Contiguous reports whether the grid's vectors are packed without gaps, in
+// which case Data is the grid flattened row by row.
+func (g Grid) Contiguous() bool {
+	return g.Stride == g.Cols*g.Depth
+}
+
+// Flatten returns the vectors concatenated row by row. Contiguous grids are
+// returned without copying.
+func (g Grid) Flatten() []float32 {
+	if g.Contiguous() {
+		return g.Data[:g.Rows*g.Cols*g.Depth]
+	}
+
+	result := make([]float32, 0, g.Rows*g.Cols*g.Depth)
+
+	for i := range g.Rows {
+		result = append(result, g.Row(i)...)
+	}
+
+	return result
+}
+
+// Nested returns the grid as nested slices that share memory with it.
+func (g Grid) Nested() [][][]float32 {
+	result := make([][][]float32, g.Rows)
+
+	for i := range result {
+		result[i] = make([][]float32, g.Cols)
+
+		for j := range result[i] {
+			result[i][j] = g.At(i, j)
+		}
+	}
+
+	return result
+}
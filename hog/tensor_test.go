@@ -0,0 +1,94 @@
+package hog_test
+
+import (
+	"testing"
+
+	"github.com/kachaje/hog/hog"
+)
+
+func TestPlane(t *testing.T) {
+	rows := [][]float32{
+		{1, 2, 3, 4},
+		{5, 6, 7, 8},
+		{9, 10, 11, 12},
+	}
+
+	p := hog.PlaneFromRows(rows)
+
+	if p.Width != 4 || p.Height != 3 || len(p.Data) != 12 {
+		t.Fatalf("Test failed. Expected: 4x3; Actual: %vx%v", p.Width, p.Height)
+	}
+
+	if p.At(2, 1) != 7 {
+		t.Fatalf("Test failed. Expected: 7; Actual: %v", p.At(2, 1))
+	}
+
+	sub := p.SubPlane(1, 1, 2, 2)
+
+	target := [][]float32{
+		{6, 7},
+		{10, 11},
+	}
+
+	for y := range target {
+		for x := range target[y] {
+			if sub.At(x, y) != target[y][x] {
+				t.Fatalf("Test failed. Expected: %v; Actual: %v", target[y][x], sub.At(x, y))
+			}
+		}
+	}
+
+	// Views share memory with their parent
+	sub.Set(0, 0, 42)
+
+	if p.At(1, 1) != 42 || p.Rows()[1][1] != 42 {
+		t.Fatalf("Test failed. Expected: 42; Actual: %v", p.At(1, 1))
+	}
+}
+
+func TestGrid(t *testing.T) {
+	data := [][][]float32{
+		{{1, 2}, {3, 4}, {5, 6}},
+		{{7, 8}, {9, 10}, {11, 12}},
+	}
+
+	g := hog.GridFromNested(data)
+
+	if g.Rows != 2 || g.Cols != 3 || g.Depth != 2 {
+		t.Fatalf("Test failed. Expected: 2x3x2; Actual: %vx%vx%v", g.Rows, g.Cols, g.Depth)
+	}
+
+	if v := g.At(1, 2); v[0] != 11 || v[1] != 12 {
+		t.Fatalf("Test failed. Expected: [11 12]; Actual: %v", v)
+	}
+
+	flat := g.Flatten()
+
+	for i := range flat {
+		if flat[i] != float32(i+1) {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", i+1, flat[i])
+		}
+	}
+
+	sub := g.SubGrid(0, 1, 2, 2)
+
+	if sub.Contiguous() {
+		t.Fatal("Test failed. Expected a strided view")
+	}
+
+	target := []float32{3, 4, 5, 6, 9, 10, 11, 12}
+
+	result := sub.Flatten()
+
+	for i := range target {
+		if result[i] != target[i] {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", target, result)
+		}
+	}
+
+	nested := sub.Nested()
+
+	if nested[1][0][1] != 10 {
+		t.Fatalf("Test failed. Expected: 10; Actual: %v", nested[1][0][1])
+	}
+}
@@ -0,0 +1,32 @@
+package hog
+
+import "image"
+
+// Trace holds every intermediate stage of one run of the pipeline.
+type Trace struct {
+	// Resized is the input fitted to the window.
+	Resized image.Image
+	Gray    *image.Gray
+	// Pixels are the gray intensities before compression.
+	Pixels    Plane
+	Magnitude Plane
+	Angle     Plane
+	Histogram Grid
+	// Blocks are the normalized blocks, with one row per block row.
+	Blocks   Grid
+	Features []float32
+	// Image is the glyph visualization returned by HOG.
+	Image image.Image
+}
+
+// Trace runs the pipeline like HOG and returns every stage in memory instead
+// of writing it out.
+func (f *HOG) Trace(img image.Image) (*Trace, error) {
+	trace := &Trace{}
+
+	if _, _, err := f.run(img, nil, trace); err != nil {
+		return nil, err
+	}
+
+	return trace, nil
+}
@@ -0,0 +1,71 @@
+package hog_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/kachaje/hog/hog"
+)
+
+func TestTrace(t *testing.T) {
+	img := loadFlower(t)
+
+	for _, opts := range [][]hog.Option{
+		nil,
+		{hog.WithCompression(hog.CompressionSqrt, 0)},
+		{hog.WithColour(true), hog.WithGaussianWeighting(true)},
+	} {
+		f, err := hog.NewHOG(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sink := hog.NewMemorySink()
+
+		hogImg, features, err := f.HOG(img, sink)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		trace, err := f.Trace(img)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(trace.Features, features) || !reflect.DeepEqual(trace.Blocks.Flatten(), features) {
+			t.Fatal("Test failed. Expected the traced features to match HOG")
+		}
+
+		if !reflect.DeepEqual(trace.Image, hogImg) {
+			t.Fatal("Test failed. Expected the traced image to match HOG")
+		}
+
+		if trace.Resized.Bounds().Dx() != 64 || trace.Gray.Bounds().Dy() != 128 {
+			t.Fatalf("Test failed. Expected: 64x128; Actual: %v", trace.Resized.Bounds())
+		}
+
+		// Every stage matches what the debug sink records
+		for name, stage := range map[string]any{
+			"outputDump.json":       trace.Pixels.Rows(),
+			"outputMagnitudes.json": trace.Magnitude.Rows(),
+			"outputAngles.json":     trace.Angle.Rows(),
+			"outputHist.json":       trace.Histogram.Nested(),
+		} {
+			payload, err := json.MarshalIndent(stage, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			target, _ := sink.Get(name)
+
+			if string(payload) != string(target) {
+				t.Fatalf("Test failed. Expected the traced stage to match %v", name)
+			}
+		}
+
+		if trace.Histogram.Rows != 16 || trace.Histogram.Cols != 8 || trace.Blocks.Rows != 15 || trace.Blocks.Cols != 7 {
+			t.Fatalf("Test failed. Expected: 16x8 and 15x7; Actual: %vx%v and %vx%v", trace.Histogram.Rows, trace.Histogram.Cols, trace.Blocks.Rows, trace.Blocks.Cols)
+		}
+	}
+}
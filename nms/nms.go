@@ -0,0 +1,164 @@
+// Package nms merges overlapping detections produced by hog.Detect.
+package nms
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"slices"
+
+	"github.com/kachaje/hog/hog"
+)
+
+// IoU returns the area of the intersection of a and b over the area of their
+// union, or 0 if both are empty.
+func IoU(a, b image.Rectangle) float64 {
+	intersection := area(a.Intersect(b))
+	union := area(a) + area(b) - intersection
+
+	if union == 0 {
+		return 0
+	}
+
+	return float64(intersection) / float64(union)
+}
+
+func area(r image.Rectangle) int {
+	if r.Empty() {
+		return 0
+	}
+
+	return r.Dx() * r.Dy()
+}
+
+// byScore returns a copy of detections sorted by descending score. Ties keep
+// their input order so the result is deterministic.
+func byScore(detections []hog.Detection) []hog.Detection {
+	sorted := slices.Clone(detections)
+
+	slices.SortStableFunc(sorted, func(a, b hog.Detection) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+
+		return 0
+	})
+
+	return sorted
+}
+
+// Greedy repeatedly keeps the highest-scoring detection left and discards
+// every other detection whose IoU with it is above threshold. The kept
+// detections are returned by descending score; the input is not modified.
+func Greedy(detections []hog.Detection, threshold float64) []hog.Detection {
+	sorted := byScore(detections)
+
+	kept := []hog.Detection{}
+
+	for _, detection := range sorted {
+		suppressed := false
+
+		for _, other := range kept {
+			if IoU(detection.Box, other.Box) > threshold {
+				suppressed = true
+				break
+			}
+		}
+
+		if !suppressed {
+			kept = append(kept, detection)
+		}
+	}
+
+	return kept
+}
+
+type Decay int
+
+const (
+	// DecayLinear multiplies the score of a detection by 1 - IoU when the IoU
+	// is above the threshold.
+	DecayLinear Decay = iota
+	// DecayGaussian multiplies the score of every detection by
+	// exp(-IoU²/sigma).
+	DecayGaussian
+)
+
+type SoftOptions struct {
+	Decay Decay
+	// Threshold is the IoU above which DecayLinear lowers a score.
+	Threshold float64
+	// Sigma is the spread of DecayGaussian. It defaults to 0.5.
+	Sigma float64
+	// MinScore is the score below which a decayed detection is dropped.
+	MinScore float32
+}
+
+// Soft runs soft non-maximum suppression: instead of discarding detections
+// that overlap a kept one, it lowers their scores according to the overlap
+// and drops them only once they fall below opts.MinScore. Scores must be
+// non-negative, since decaying a negative score would raise it. The kept
+// detections carry their decayed scores and are returned in the order they
+// were selected; the input is not modified.
+func Soft(detections []hog.Detection, opts SoftOptions) ([]hog.Detection, error) {
+	if opts.Decay == DecayGaussian && opts.Sigma == 0 {
+		opts.Sigma = 0.5
+	}
+
+	switch {
+	case opts.Decay != DecayLinear && opts.Decay != DecayGaussian:
+		return nil, fmt.Errorf("unknown decay %d", opts.Decay)
+	case opts.Sigma < 0:
+		return nil, fmt.Errorf("sigma must not be negative, got %v", opts.Sigma)
+	}
+
+	for _, detection := range detections {
+		if detection.Score < 0 {
+			return nil, fmt.Errorf("soft suppression needs non-negative scores, got %v", detection.Score)
+		}
+	}
+
+	remaining := slices.Clone(detections)
+
+	kept := []hog.Detection{}
+
+	for len(remaining) > 0 {
+		best := 0
+		for i, detection := range remaining {
+			if detection.Score > remaining[best].Score {
+				best = i
+			}
+		}
+
+		top := remaining[best]
+		kept = append(kept, top)
+
+		remaining = slices.Delete(remaining, best, best+1)
+
+		next := remaining[:0]
+
+		for _, detection := range remaining {
+			overlap := IoU(top.Box, detection.Box)
+
+			switch opts.Decay {
+			case DecayLinear:
+				if overlap > opts.Threshold {
+					detection.Score *= float32(1 - overlap)
+				}
+			case DecayGaussian:
+				detection.Score *= float32(math.Exp(-overlap * overlap / opts.Sigma))
+			}
+
+			if detection.Score >= opts.MinScore {
+				next = append(next, detection)
+			}
+		}
+
+		remaining = next
+	}
+
+	return kept, nil
+}
@@ -0,0 +1,102 @@
+package nms_test
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/kachaje/hog/hog"
+	"github.com/kachaje/hog/nms"
+)
+
+func TestIoU(t *testing.T) {
+	for _, row := range []struct {
+		a, b   image.Rectangle
+		target float64
+	}{
+		{image.Rect(0, 0, 10, 10), image.Rect(0, 0, 10, 10), 1},
+		{image.Rect(0, 0, 10, 10), image.Rect(5, 0, 15, 10), 50.0 / 150},
+		{image.Rect(0, 0, 10, 10), image.Rect(20, 20, 30, 30), 0},
+		{image.Rectangle{}, image.Rectangle{}, 0},
+	} {
+		result := nms.IoU(row.a, row.b)
+
+		if math.Abs(result-row.target) > 1e-9 {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", row.target, result)
+		}
+	}
+}
+
+func detections() []hog.Detection {
+	return []hog.Detection{
+		{Box: image.Rect(0, 0, 10, 10), Score: 0.8},
+		{Box: image.Rect(1, 0, 11, 10), Score: 0.9},
+		{Box: image.Rect(5, 0, 15, 10), Score: 0.7},
+		{Box: image.Rect(40, 40, 50, 50), Score: 0.5},
+	}
+}
+
+func TestGreedy(t *testing.T) {
+	input := detections()
+
+	result := nms.Greedy(input, 0.5)
+
+	target := []float32{0.9, 0.7, 0.5}
+
+	if len(result) != len(target) {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", len(target), len(result))
+	}
+
+	for i, detection := range result {
+		if detection.Score != target[i] {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", target[i], detection.Score)
+		}
+	}
+
+	// A stricter threshold also drops the box overlapping the best by 60/140
+	if result := nms.Greedy(input, 0.3); len(result) != 2 {
+		t.Fatalf("Test failed. Expected: 2; Actual: %v", len(result))
+	}
+
+	if input[0].Score != 0.8 {
+		t.Fatal("Test failed. Expected the input to be left unchanged")
+	}
+}
+
+func TestSoft(t *testing.T) {
+	result, err := nms.Soft(detections(), nms.SoftOptions{Decay: nms.DecayLinear, Threshold: 0.3, MinScore: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// IoU with the best box is 90/110 for the first and 60/140 for the third.
+	// The isolated box is selected next, then the third, whose overlap of
+	// 50/150 with the first pushes the first below the minimum score.
+	third := 0.7 * (1 - 60.0/140)
+
+	target := []float64{0.9, 0.5, third}
+
+	if len(result) != len(target) {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", len(target), len(result))
+	}
+
+	for i, detection := range result {
+		if math.Abs(float64(detection.Score)-target[i]) > 1e-6 {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v", target[i], detection.Score)
+		}
+	}
+
+	result, err = nms.Soft(detections(), nms.SoftOptions{Decay: nms.DecayGaussian, MinScore: 0.6})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every other box either decays below 0.6 or starts below it
+	if len(result) != 1 || result[0].Score != 0.9 {
+		t.Fatalf("Test failed. Expected: [0.9]; Actual: %v", result)
+	}
+
+	if _, err := nms.Soft([]hog.Detection{{Score: -1}}, nms.SoftOptions{}); err == nil {
+		t.Fatal("Test failed. Expected an error for a negative score")
+	}
+}
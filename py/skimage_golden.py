@@ -0,0 +1,198 @@
+#!/usr/bin/env python3
+
+# Writes the golden vectors of hog/fixtures/skimage/golden.json, used by the
+# Go tests of the skimage semantics.
+#
+# The vectors come from a line-by-line pure Python port of
+# skimage.feature.hog (skimage/feature/_hog.py and _hoghistogram.pyx), and
+# every one of them is compared with skimage.feature.hog itself: the script
+# fails if they disagree or if numpy and scikit-image are not installed. The
+# scikit-image version used for the comparison is recorded in golden.json.
+#
+# Usage: pip install numpy scikit-image
+#        python3 skimage_golden.py [../hog/fixtures/skimage]
+
+import json
+import math
+import os
+import sys
+
+EPS = 1e-5
+
+CASES = [
+    {"name": "default"},
+    {"name": "l1", "block_norm": "L1"},
+    {"name": "l1-sqrt", "block_norm": "L1-sqrt"},
+    {"name": "l2", "block_norm": "L2"},
+    {"name": "sqrt", "transform_sqrt": True},
+    {
+        "name": "layout",
+        "orientations": 6,
+        "pixels_per_cell": [6, 6],
+        "cells_per_block": [2, 2],
+        "block_norm": "L2",
+    },
+    # 45 degrees, common in 8-bit images, is a bin edge with 4 and 12 bins
+    {"name": "4-bins", "orientations": 4, "cells_per_block": [2, 2], "block_norm": "L2"},
+    {"name": "12-bins", "orientations": 12, "cells_per_block": [2, 2], "block_norm": "L2"},
+]
+
+
+def gradients(image):
+    rows, cols = len(image), len(image[0])
+
+    g_row = [[0.0] * cols for _ in range(rows)]
+    g_col = [[0.0] * cols for _ in range(rows)]
+
+    for r in range(1, rows - 1):
+        for c in range(cols):
+            g_row[r][c] = image[r + 1][c] - image[r - 1][c]
+
+    for r in range(rows):
+        for c in range(1, cols - 1):
+            g_col[r][c] = image[r][c + 1] - image[r][c - 1]
+
+    return g_row, g_col
+
+
+def cell_histograms(magnitude, orientation, orientations, c_row, c_col, n_cells_row, n_cells_col):
+    per_bin = 180.0 / orientations
+
+    hist = [[[0.0] * orientations for _ in range(n_cells_col)] for _ in range(n_cells_row)]
+
+    for i in range(orientations):
+        start = per_bin * i
+        end = per_bin * (i + 1)
+
+        for r in range(n_cells_row):
+            for c in range(n_cells_col):
+                total = 0.0
+
+                for y in range(r * c_row, (r + 1) * c_row):
+                    for x in range(c * c_col, (c + 1) * c_col):
+                        if orientation[y][x] >= end or orientation[y][x] < start:
+                            continue
+
+                        total += magnitude[y][x]
+
+                hist[r][c][i] = total / (c_row * c_col)
+
+    return hist
+
+
+def normalize(block, method):
+    if method == "L1":
+        k = sum(abs(x) for x in block) + EPS
+        return [x / k for x in block]
+
+    if method == "L1-sqrt":
+        k = sum(abs(x) for x in block) + EPS
+        return [math.sqrt(x / k) for x in block]
+
+    if method == "L2":
+        k = math.sqrt(sum(x * x for x in block) + EPS**2)
+        return [x / k for x in block]
+
+    if method == "L2-Hys":
+        k = math.sqrt(sum(x * x for x in block) + EPS**2)
+        out = [min(x / k, 0.2) for x in block]
+        k = math.sqrt(sum(x * x for x in out) + EPS**2)
+        return [x / k for x in out]
+
+    raise ValueError(f"unknown block_norm {method}")
+
+
+def hog(image, orientations=9, pixels_per_cell=(8, 8), cells_per_block=(3, 3), block_norm="L2-Hys", transform_sqrt=False):
+    if transform_sqrt:
+        image = [[math.sqrt(x) for x in row] for row in image]
+
+    g_row, g_col = gradients(image)
+
+    rows, cols = len(image), len(image[0])
+    c_row, c_col = pixels_per_cell
+    b_row, b_col = cells_per_block
+
+    n_cells_row = rows // c_row
+    n_cells_col = cols // c_col
+
+    magnitude = [[math.hypot(g_col[r][c], g_row[r][c]) for c in range(cols)] for r in range(rows)]
+    orientation = [[math.degrees(math.atan2(g_row[r][c], g_col[r][c])) % 180 for c in range(cols)] for r in range(rows)]
+
+    hist = cell_histograms(magnitude, orientation, orientations, c_row, c_col, n_cells_row, n_cells_col)
+
+    n_blocks_row = (n_cells_row - b_row) + 1
+    n_blocks_col = (n_cells_col - b_col) + 1
+
+    features = []
+
+    for r in range(n_blocks_row):
+        for c in range(n_blocks_col):
+            block = []
+
+            for y in range(r, r + b_row):
+                for x in range(c, c + b_col):
+                    block.extend(hist[y][x])
+
+            features.extend(normalize(block, block_norm))
+
+    return features
+
+
+def parameters(case):
+    return {
+        "orientations": case.get("orientations", 9),
+        "pixels_per_cell": case.get("pixels_per_cell", [8, 8]),
+        "cells_per_block": case.get("cells_per_block", [3, 3]),
+        "block_norm": case.get("block_norm", "L2-Hys"),
+        "transform_sqrt": case.get("transform_sqrt", False),
+    }
+
+
+def check_against_skimage(image, params, features):
+    import numpy as np
+    from skimage.feature import hog as skimage_hog
+
+    expected = skimage_hog(
+        np.array(image),
+        orientations=params["orientations"],
+        pixels_per_cell=tuple(params["pixels_per_cell"]),
+        cells_per_block=tuple(params["cells_per_block"]),
+        block_norm=params["block_norm"],
+        transform_sqrt=params["transform_sqrt"],
+        feature_vector=True,
+    )
+
+    if not np.allclose(expected, features, rtol=0, atol=1e-9):
+        raise SystemExit(f"port disagrees with skimage.feature.hog for {params}")
+
+
+if __name__ == "__main__":
+    try:
+        import numpy  # noqa: F401
+        import skimage
+    except ImportError:
+        raise SystemExit("the cross-check needs numpy and scikit-image: pip install numpy scikit-image")
+
+    folder = sys.argv[1] if len(sys.argv) > 1 else os.path.join("..", "hog", "fixtures", "skimage")
+
+    with open(os.path.join(folder, "image.json")) as f:
+        pixels = json.load(f)
+
+    # img_as_float of a uint8 image
+    image = [[x / 255.0 for x in row] for row in pixels]
+
+    cases = []
+
+    for case in CASES:
+        params = parameters(case)
+
+        features = hog(image, **params)
+
+        check_against_skimage(image, params, features)
+
+        cases.append({"name": case["name"], **params, "features": [round(x, 12) for x in features]})
+
+        print(f"{case['name']}: {len(features)} features")
+
+    with open(os.path.join(folder, "golden.json"), "w") as f:
+        json.dump({"skimage": skimage.__version__, "cases": cases}, f)
@@ -0,0 +1,117 @@
+package svm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kachaje/hog/hog"
+)
+
+// FormatVersion is the version written to new model files. Load refuses
+// files with any other version. Version 2 stores the enums of the
+// configuration by name.
+const FormatVersion = 2
+
+// Detector is a trained model together with the extractor whose descriptors
+// it was trained on.
+type Detector struct {
+	Model *Model
+	// Labels names the classes, keyed by the label Predict returns.
+	Labels map[int]string
+	HOG    *hog.HOG
+}
+
+// file is the on-disk layout of a Detector.
+type file struct {
+	Version int            `json:"version"`
+	Config  hog.Config     `json:"config"`
+	Labels  map[int]string `json:"labels,omitempty"`
+	Weights []float32      `json:"weights"`
+	Bias    float32        `json:"bias"`
+}
+
+func checkLength(model *Model, extractor *hog.HOG) error {
+	if len(model.Weights) != extractor.FeatureLength() {
+		return fmt.Errorf("model has %d weights but the extractor produces %d features", len(model.Weights), extractor.FeatureLength())
+	}
+
+	return nil
+}
+
+// Save writes the detector as JSON, including the full configuration of its
+// extractor.
+func (d *Detector) Save(w io.Writer) error {
+	if err := checkLength(d.Model, d.HOG); err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(file{
+		Version: FormatVersion,
+		Config:  d.HOG.Config(),
+		Labels:  d.Labels,
+		Weights: d.Model.Weights,
+		Bias:    d.Model.Bias,
+	})
+}
+
+// SaveFile writes the detector to the file at path.
+func (d *Detector) SaveFile(path string) error {
+	writer, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := d.Save(writer); err != nil {
+		writer.Close()
+		return err
+	}
+
+	return writer.Close()
+}
+
+// Load reads a detector written by Save and rebuilds its extractor from the
+// stored configuration. Unknown enum names are an error.
+func Load(r io.Reader) (*Detector, error) {
+	var data file
+
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, err
+	}
+
+	if data.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported model version %d, expected %d", data.Version, FormatVersion)
+	}
+
+	extractor, err := hog.FromConfig(data.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &Model{hog.LinearModel{Weights: data.Weights, Bias: data.Bias}}
+
+	if err := checkLength(model, extractor); err != nil {
+		return nil, err
+	}
+
+	return &Detector{
+		Model:  model,
+		Labels: data.Labels,
+		HOG:    extractor,
+	}, nil
+}
+
+// LoadFile reads a detector from the file at path.
+func LoadFile(path string) (*Detector, error) {
+	reader, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return Load(reader)
+}
@@ -0,0 +1,109 @@
+package svm_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kachaje/hog/hog"
+	"github.com/kachaje/hog/svm"
+)
+
+func detector(t *testing.T) *svm.Detector {
+	extractor, err := hog.NewHOG(hog.WithWindow(32, 32), hog.WithBlockNorm(hog.NormL2Hys), hog.WithSigned(true), hog.WithBins(12))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weights := make([]float32, extractor.FeatureLength())
+	for i := range weights {
+		weights[i] = float32(i) / 100
+	}
+
+	return &svm.Detector{
+		Model:  &svm.Model{LinearModel: hog.LinearModel{Weights: weights, Bias: -0.25}},
+		Labels: map[int]string{1: "flower", -1: "background"},
+		HOG:    extractor,
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	original := detector(t)
+
+	path := filepath.Join(t.TempDir(), "model.json")
+
+	if err := original.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := svm.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(loaded.Model, original.Model) {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", original.Model, loaded.Model)
+	}
+
+	if !reflect.DeepEqual(loaded.Labels, original.Labels) {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", original.Labels, loaded.Labels)
+	}
+
+	if loaded.HOG.Config() != original.HOG.Config() {
+		t.Fatalf("Test failed. Expected: %#v; Actual: %#v", original.HOG.Config(), loaded.HOG.Config())
+	}
+}
+
+func TestLoadMismatch(t *testing.T) {
+	var buffer bytes.Buffer
+
+	if err := detector(t).Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	var data map[string]any
+
+	if err := json.Unmarshal(buffer.Bytes(), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	// edit returns a copy of object with key set to value
+	edit := func(object map[string]any, key string, value any) map[string]any {
+		copied := map[string]any{}
+		for k, v := range object {
+			copied[k] = v
+		}
+
+		copied[key] = value
+
+		return copied
+	}
+
+	config := data["config"].(map[string]any)
+
+	for _, changed := range []map[string]any{
+		edit(data, "version", 1),
+		edit(data, "weights", []float32{1, 2, 3}),
+		edit(data, "config", edit(config, "cellSize", 4)),
+		edit(data, "config", edit(config, "bins", 7)),
+		edit(data, "config", edit(config, "blockNorm", "l3")),
+		edit(data, "config", edit(config, "blockNorm", 1)),
+		edit(data, "config", edit(config, "resampler", "lanczos")),
+		edit(data, "config", edit(config, "semantics", "opencv")),
+	} {
+		content, err := json.Marshal(changed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := svm.Load(bytes.NewReader(content)); err == nil {
+			t.Fatalf("Test failed. Expected an error loading %s", content[:80])
+		}
+	}
+
+	if err := (&svm.Detector{Model: &svm.Model{}, HOG: detector(t).HOG}).Save(&buffer); err == nil {
+		t.Fatal("Test failed. Expected an error saving a model of the wrong length")
+	}
+}
@@ -0,0 +1,203 @@
+package svm
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"image/draw"
+	"slices"
+
+	"github.com/kachaje/hog/hog"
+)
+
+type MiningOptions struct {
+	// Train configures every training run.
+	Train Options
+	// Rounds is the number of times negative images are scanned and the
+	// model retrained. 0 only trains the initial model.
+	Rounds int
+	// Stride is the window stride used to scan negative images. It defaults
+	// to the distance between two blocks.
+	Stride int
+	// Threshold is the score from which a negative window counts as a false
+	// positive.
+	Threshold float32
+	// PerImage caps the hardest negatives kept from one image in a round. 0
+	// keeps them all.
+	PerImage int
+	// PerRound caps the hardest negatives added in one round. 0 keeps them
+	// all.
+	PerRound int
+	// Progress, if set, is called after every round with the number of
+	// negatives it added.
+	Progress func(round, mined int)
+}
+
+// candidate is a false positive found while scanning a negative image.
+type candidate struct {
+	image int
+	x, y  int
+	score float32
+}
+
+func byHardness(a, b candidate) int {
+	return cmp.Compare(b.score, a.score)
+}
+
+// Mine bootstraps a detector: it trains on the positive and negative window
+// images, scans every window of the negative images for false positives,
+// adds the highest-scoring ones to the negatives and retrains, for
+// opts.Rounds rounds or until a round finds nothing new. A window is never
+// added twice.
+//
+// Every training descriptor comes from extractor.HOG on a window image: the
+// scan scores windows with Dense, like Detect, but a mined window is cropped
+// out of its image before its descriptor is extracted, because Dense differs
+// from HOG on the border cells of a window.
+func Mine(extractor *hog.HOG, positives, negatives []image.Image, images []hog.Source, opts MiningOptions) (*Model, error) {
+	config := extractor.Config()
+
+	if opts.Stride == 0 {
+		opts.Stride = config.CellSize * config.BlockStride
+	}
+
+	if opts.Rounds < 0 || opts.PerImage < 0 || opts.PerRound < 0 {
+		return nil, fmt.Errorf("rounds and caps must not be negative")
+	}
+
+	samples := make([][]float32, 0, len(positives)+len(negatives))
+
+	for i, window := range slices.Concat(positives, negatives) {
+		_, features, err := extractor.HOG(window, nil)
+		if err != nil {
+			return nil, fmt.Errorf("window %d: %w", i, err)
+		}
+
+		samples = append(samples, features)
+	}
+
+	labels := make([]int, len(samples))
+
+	for i := range labels {
+		labels[i] = -1
+		if i < len(positives) {
+			labels[i] = 1
+		}
+	}
+
+	model, err := Train(samples, labels, opts.Train)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkLength(model, extractor); err != nil {
+		return nil, err
+	}
+
+	type position struct{ image, x, y int }
+
+	mined := map[position]bool{}
+
+	for round := range opts.Rounds {
+		candidates := []candidate{}
+
+		for i, source := range images {
+			img, err := source.Open()
+			if err != nil {
+				return nil, fmt.Errorf("negative image %d: %w", i, err)
+			}
+
+			windows, err := extractor.Dense(img, opts.Stride)
+			if err != nil {
+				return nil, err
+			}
+
+			found := []candidate{}
+
+			for _, window := range windows {
+				if mined[position{i, window.X, window.Y}] {
+					continue
+				}
+
+				score := model.Score(window.Features)
+
+				if score >= opts.Threshold {
+					found = append(found, candidate{i, window.X, window.Y, score})
+				}
+			}
+
+			slices.SortStableFunc(found, byHardness)
+
+			if opts.PerImage > 0 && len(found) > opts.PerImage {
+				found = found[:opts.PerImage]
+			}
+
+			candidates = append(candidates, found...)
+		}
+
+		slices.SortStableFunc(candidates, byHardness)
+
+		if opts.PerRound > 0 && len(candidates) > opts.PerRound {
+			candidates = candidates[:opts.PerRound]
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(round+1, len(candidates))
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+
+		// Only the images that kept a window are opened a second time
+		opened := map[int]image.Image{}
+
+		for _, c := range candidates {
+			mined[position{c.image, c.x, c.y}] = true
+
+			img, ok := opened[c.image]
+			if !ok {
+				img, err = images[c.image].Open()
+				if err != nil {
+					return nil, fmt.Errorf("negative image %d: %w", c.image, err)
+				}
+
+				opened[c.image] = img
+			}
+
+			corner := img.Bounds().Min.Add(image.Pt(c.x, c.y))
+			window := image.Rectangle{corner, corner.Add(image.Pt(config.Width, config.Height))}
+
+			_, features, err := extractor.HOG(crop(img, window), nil)
+			if err != nil {
+				return nil, fmt.Errorf("negative image %d: %w", c.image, err)
+			}
+
+			samples = append(samples, features)
+			labels = append(labels, -1)
+		}
+
+		model, err = Train(samples, labels, opts.Train)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return model, nil
+}
+
+// crop returns the part of img inside r, sharing its pixels when img can
+// return a sub-image.
+func crop(img image.Image, r image.Rectangle) image.Image {
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(r)
+	}
+
+	window := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+
+	draw.Draw(window, window.Rect, img, r.Min, draw.Src)
+
+	return window
+}
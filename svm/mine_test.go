@@ -0,0 +1,207 @@
+package svm_test
+
+import (
+	"image"
+	"image/color"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/kachaje/hog/hog"
+	"github.com/kachaje/hog/svm"
+)
+
+func TestMine(t *testing.T) {
+	extractor, err := hog.NewHOG(hog.WithWindow(16, 16), hog.WithBlockNorm(hog.NormL2Hys), hog.WithSpatialInterpolation(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	random := rand.New(rand.NewPCG(3, 0))
+
+	features := func(img image.Image) []float32 {
+		_, result, err := extractor.HOG(img, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return result
+	}
+
+	// Positives are vertical edges, the initial negatives horizontal ones
+	positives := []image.Image{}
+	negatives := []image.Image{}
+
+	for i := range 20 {
+		edge := image.NewGray(image.Rect(0, 0, 16, 16))
+		horizontal := image.NewGray(image.Rect(0, 0, 16, 16))
+
+		for y := range 16 {
+			for x := range 16 {
+				value := uint8(40 + i*5 + random.IntN(10))
+				if x >= 4+i%8 {
+					value += 120
+				}
+
+				edge.SetGray(x, y, color.Gray{value})
+				horizontal.SetGray(y, x, color.Gray{value})
+			}
+		}
+
+		positives = append(positives, edge)
+		negatives = append(negatives, horizontal)
+	}
+
+	// The negative images are noise, which the initial model has never seen
+	images := []hog.Source{}
+
+	for range 3 {
+		noise := image.NewGray(image.Rect(0, 0, 64, 64))
+
+		for i := range noise.Pix {
+			noise.Pix[i] = uint8(random.IntN(256))
+		}
+
+		images = append(images, hog.SourceFunc(func() (image.Image, error) {
+			return noise, nil
+		}))
+	}
+
+	falsePositives := func(model *svm.Model) int {
+		count := 0
+
+		for _, source := range images {
+			img, _ := source.Open()
+
+			windows, err := extractor.Dense(img, 8)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, window := range windows {
+				if model.Score(window.Features) >= 0 {
+					count++
+				}
+			}
+		}
+
+		return count
+	}
+
+	opts := svm.MiningOptions{Train: svm.Options{C: 1, Bias: 1, Seed: 1}}
+
+	initial, err := svm.Mine(extractor, positives, negatives, images, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts.Rounds = 3
+	opts.PerImage = 5
+	opts.PerRound = 12
+
+	rounds := []int{}
+	opts.Progress = func(round, mined int) {
+		rounds = append(rounds, mined)
+	}
+
+	mined, err := svm.Mine(extractor, positives, negatives, images, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rounds) == 0 || rounds[0] != 12 {
+		t.Fatalf("Test failed. Expected a first round of 12; Actual: %v", rounds)
+	}
+
+	for _, count := range rounds {
+		if count > 12 {
+			t.Fatalf("Test failed. Expected at most 12 per round; Actual: %v", rounds)
+		}
+	}
+
+	before := falsePositives(initial)
+	after := falsePositives(mined)
+
+	if after >= before {
+		t.Fatalf("Test failed. Expected fewer than %v false positives; Actual: %v", before, after)
+	}
+
+	for _, sample := range positives {
+		if mined.Predict(features(sample)) != 1 {
+			t.Fatal("Test failed. Expected the positives to stay positive")
+		}
+	}
+}
+
+func TestMineExtractsCrops(t *testing.T) {
+	extractor, err := hog.NewHOG(hog.WithWindow(16, 16), hog.WithBlockNorm(hog.NormL2Hys), hog.WithSpatialInterpolation(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	random := rand.New(rand.NewPCG(5, 0))
+
+	noise := func(width, height int) *image.Gray {
+		img := image.NewGray(image.Rect(0, 0, width, height))
+
+		for i := range img.Pix {
+			img.Pix[i] = uint8(random.IntN(256))
+		}
+
+		return img
+	}
+
+	positives := []image.Image{noise(16, 16), noise(16, 16)}
+	negatives := []image.Image{noise(16, 16), noise(16, 16)}
+	scene := noise(32, 32)
+
+	opts := svm.MiningOptions{Train: svm.Options{C: 1, Bias: 1, Seed: 1}, Threshold: -100, PerRound: 1}
+
+	initial, err := svm.Mine(extractor, positives, negatives, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The window Mine picks is the highest-scoring one of the dense scan
+	windows, err := extractor.Dense(scene, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hardest := windows[0]
+	for _, window := range windows {
+		if initial.Score(window.Features) > initial.Score(hardest.Features) {
+			hardest = window
+		}
+	}
+
+	// but its descriptor must come from the cropped window, like every
+	// other sample
+	samples := [][]float32{}
+	for _, img := range slices.Concat(positives, negatives, []image.Image{scene.SubImage(image.Rect(hardest.X, hardest.Y, hardest.X+16, hardest.Y+16))}) {
+		_, features, err := extractor.HOG(img, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		samples = append(samples, features)
+	}
+
+	expected, err := svm.Train(samples, []int{1, 1, -1, -1, -1}, opts.Train)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts.Rounds = 1
+
+	mined, err := svm.Mine(extractor, positives, negatives, []hog.Source{hog.SourceFunc(func() (image.Image, error) {
+		return scene, nil
+	})}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(mined.Weights, expected.Weights) || mined.Bias != expected.Bias {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", expected.LinearModel, mined.LinearModel)
+	}
+}
@@ -0,0 +1,169 @@
+// Package svm trains linear support vector machines on HOG descriptors.
+package svm
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"github.com/kachaje/hog/hog"
+)
+
+// Model is a trained binary classifier. It scores descriptors like any
+// hog.LinearModel, so it can be passed to hog.Detect directly.
+type Model struct {
+	hog.LinearModel
+}
+
+// Predict returns 1 if features score on the positive side of the margin and
+// -1 otherwise.
+func (m Model) Predict(features []float32) int {
+	if m.Score(features) > 0 {
+		return 1
+	}
+
+	return -1
+}
+
+type Options struct {
+	// C is the penalty on margin violations. It defaults to 1.
+	C float64
+	// Bias is the value of the constant feature appended to every sample to
+	// learn the bias term. 0 trains a model without bias.
+	Bias float64
+	// Epochs caps the number of passes over the samples. It defaults to 1000.
+	Epochs int
+	// Tolerance is the largest projected gradient spread accepted as
+	// converged. It defaults to 0.1.
+	Tolerance float64
+	// Seed fixes the order the samples are visited in, which makes training
+	// deterministic.
+	Seed uint64
+}
+
+// Train fits a linear SVM with hinge loss by dual coordinate descent. Labels
+// must be 1 or -1 and every sample must have the same length.
+func Train(samples [][]float32, labels []int, opts Options) (*Model, error) {
+	if opts.C == 0 {
+		opts.C = 1
+	}
+
+	if opts.Epochs == 0 {
+		opts.Epochs = 1000
+	}
+
+	if opts.Tolerance == 0 {
+		opts.Tolerance = 0.1
+	}
+
+	switch {
+	case len(samples) == 0:
+		return nil, fmt.Errorf("no samples to train on")
+	case len(samples) != len(labels):
+		return nil, fmt.Errorf("got %d samples but %d labels", len(samples), len(labels))
+	case opts.C < 0:
+		return nil, fmt.Errorf("C must be positive, got %v", opts.C)
+	case opts.Epochs < 0:
+		return nil, fmt.Errorf("epochs must be positive, got %d", opts.Epochs)
+	case opts.Tolerance < 0:
+		return nil, fmt.Errorf("tolerance must be positive, got %v", opts.Tolerance)
+	}
+
+	length := len(samples[0])
+
+	for i, sample := range samples {
+		if len(sample) != length {
+			return nil, fmt.Errorf("sample %d has %d features, expected %d", i, len(sample), length)
+		}
+
+		if labels[i] != 1 && labels[i] != -1 {
+			return nil, fmt.Errorf("label %d is %d, expected 1 or -1", i, labels[i])
+		}
+	}
+
+	// The weights are accumulated in float64 with the bias weight last
+	weights := make([]float64, length+1)
+	alpha := make([]float64, len(samples))
+
+	// Diagonal of the dual problem's kernel matrix
+	diagonal := make([]float64, len(samples))
+
+	for i, sample := range samples {
+		diagonal[i] = opts.Bias * opts.Bias
+
+		for _, value := range sample {
+			diagonal[i] += float64(value) * float64(value)
+		}
+	}
+
+	order := make([]int, len(samples))
+	for i := range order {
+		order[i] = i
+	}
+
+	random := rand.New(rand.NewPCG(opts.Seed, 0))
+
+	for range opts.Epochs {
+		random.Shuffle(len(order), func(i, j int) {
+			order[i], order[j] = order[j], order[i]
+		})
+
+		maxGradient := math.Inf(-1)
+		minGradient := math.Inf(1)
+
+		for _, i := range order {
+			if diagonal[i] == 0 {
+				continue
+			}
+
+			y := float64(labels[i])
+
+			margin := weights[length] * opts.Bias
+			for k, value := range samples[i] {
+				margin += weights[k] * float64(value)
+			}
+
+			gradient := y*margin - 1
+
+			projected := gradient
+			switch {
+			case alpha[i] == 0:
+				projected = min(gradient, 0)
+			case alpha[i] == opts.C:
+				projected = max(gradient, 0)
+			}
+
+			maxGradient = max(maxGradient, projected)
+			minGradient = min(minGradient, projected)
+
+			if math.Abs(projected) < 1e-12 {
+				continue
+			}
+
+			previous := alpha[i]
+			alpha[i] = min(max(alpha[i]-gradient/diagonal[i], 0), opts.C)
+
+			step := (alpha[i] - previous) * y
+
+			for k, value := range samples[i] {
+				weights[k] += step * float64(value)
+			}
+			weights[length] += step * opts.Bias
+		}
+
+		if maxGradient-minGradient <= opts.Tolerance {
+			break
+		}
+	}
+
+	model := &Model{hog.LinearModel{
+		Weights: make([]float32, length),
+		Bias:    float32(weights[length] * opts.Bias),
+	}}
+
+	for k := range length {
+		model.Weights[k] = float32(weights[k])
+	}
+
+	return model, nil
+}
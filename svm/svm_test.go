@@ -0,0 +1,90 @@
+package svm_test
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"testing"
+
+	"github.com/kachaje/hog/svm"
+)
+
+// separable returns points on either side of the line x + y = 3, so the
+// classifier needs its bias term.
+func separable() ([][]float32, []int) {
+	random := rand.New(rand.NewPCG(7, 0))
+
+	samples := [][]float32{}
+	labels := []int{}
+
+	for len(samples) < 200 {
+		x := random.Float32() * 3
+		y := random.Float32() * 3
+
+		switch {
+		case x+y > 3.5:
+			samples = append(samples, []float32{x, y})
+			labels = append(labels, 1)
+		case x+y < 2.5:
+			samples = append(samples, []float32{x, y})
+			labels = append(labels, -1)
+		}
+	}
+
+	return samples, labels
+}
+
+func TestTrain(t *testing.T) {
+	samples, labels := separable()
+
+	model, err := svm.Train(samples, labels, svm.Options{C: 10, Bias: 1, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, sample := range samples {
+		if result := model.Predict(sample); result != labels[i] {
+			t.Fatalf("Test failed. Expected: %v; Actual: %v for %v", labels[i], result, sample)
+		}
+	}
+
+	if model.Bias >= 0 {
+		t.Fatalf("Test failed. Expected a negative bias; Actual: %v", model.Bias)
+	}
+
+	again, err := svm.Train(samples, labels, svm.Options{C: 10, Bias: 1, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(model, again) {
+		t.Fatalf("Test failed. Expected: %v; Actual: %v", model, again)
+	}
+
+	// Without a bias the boundary has to pass through the origin
+	unbiased, err := svm.Train(samples, labels, svm.Options{C: 10, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if unbiased.Bias != 0 {
+		t.Fatalf("Test failed. Expected: 0; Actual: %v", unbiased.Bias)
+	}
+}
+
+func TestTrainValidation(t *testing.T) {
+	for _, row := range []struct {
+		samples [][]float32
+		labels  []int
+		opts    svm.Options
+	}{
+		{nil, nil, svm.Options{}},
+		{[][]float32{{1}}, []int{1, -1}, svm.Options{}},
+		{[][]float32{{1}, {1, 2}}, []int{1, -1}, svm.Options{}},
+		{[][]float32{{1}, {2}}, []int{1, 0}, svm.Options{}},
+		{[][]float32{{1}, {2}}, []int{1, -1}, svm.Options{C: -1}},
+	} {
+		if _, err := svm.Train(row.samples, row.labels, row.opts); err == nil {
+			t.Fatalf("Test failed. Expected an error for %v", row)
+		}
+	}
+}